@@ -1,6 +1,7 @@
 package si
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -15,10 +16,16 @@ type SetConf struct {
 	value  any
 }
 
+// Do will execute the update.
 func (s *S[T]) Do(db DB) error {
-	query := s.buildSet()
-	log(query, s.q.args)
-	_, err := db.Exec(query, s.q.args...)
+	return s.DoContext(context.Background(), db)
+}
+
+// DoContext is same as Do, but with a context.
+func (s *S[T]) DoContext(ctx context.Context, db DB) error {
+	c := clientOf(db)
+	query := s.buildSet(c)
+	_, err := c.exec(ctx, db, query, s.q.args...)
 	if err != nil {
 		return fmt.Errorf("si.set: execute query: %w", err)
 	}
@@ -61,21 +68,20 @@ func (s *S[T]) Set(column string, value any) *S[T] {
 	return s
 }
 
-func (s *S[T]) buildSet() string {
+func (s *S[T]) buildSet(c *Client) string {
 	t := new(T)
 	table := (*t).GetTable()
+	d := c.dialect
+	s.q.client = c
+	s.q.args = nil
+	s.q.argsCounter = 0
 
 	// Update
-	query := "UPDATE " + table
+	query := "UPDATE " + d.Quote(table)
 
 	// Join
 	for _, jf := range s.q.joins {
-		j := jf(*t)
-		if config.useDeletedAt && !s.q.withDeleted {
-			j.Condition = append(j.Condition, filter{Column: j.Table + ".deleted_at", Operation: "IS", Value: nil})
-		}
-		condition := s.q.buildFilters(j.Condition)
-		query += fmt.Sprintf(" %s JOIN %s ON%s", j.JoinType, j.Table, condition)
+		query += s.q.buildJoin(jf(*t))
 	}
 
 	// Set
@@ -85,9 +91,7 @@ func (s *S[T]) buildSet() string {
 		if _, ok := set.value.(Raw); ok {
 			list = append(list, fmt.Sprintf("%s = %s", set.column, set.value))
 		} else {
-			s.q.argsCounter += 1
-			list = append(list, fmt.Sprintf("%s = $%d", set.column, s.q.argsCounter))
-			s.q.args = append(s.q.args, set.value)
+			list = append(list, fmt.Sprintf("%s = %s", set.column, s.q.arg(set.value)))
 		}
 	}
 	query += strings.Join(list, ",")
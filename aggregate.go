@@ -0,0 +1,150 @@
+package si
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Count will return the number of rows that matches the query.
+// `OrderBy`, `Take` and `Skip` are ignored.
+func (q *Q[T]) Count(db DB) (int64, error) {
+	return q.CountContext(context.Background(), db)
+}
+
+// CountContext is same as Count, but with a context.
+func (q *Q[T]) CountContext(ctx context.Context, db DB) (int64, error) {
+	var result int64
+	c := clientOf(db)
+	err := q.aggregate(ctx, db, c, "SELECT COUNT(*)"+q.buildFrom(c, 0), &result)
+	if err != nil {
+		return 0, fmt.Errorf("si.count: %w", err)
+	}
+	return result, nil
+}
+
+// Exists will return true if there is at least one row that matches the query.
+// `OrderBy`, `Take` and `Skip` are ignored.
+func (q *Q[T]) Exists(db DB) (bool, error) {
+	return q.ExistsContext(context.Background(), db)
+}
+
+// ExistsContext is same as Exists, but with a context.
+func (q *Q[T]) ExistsContext(ctx context.Context, db DB) (bool, error) {
+	var result bool
+	c := clientOf(db)
+	err := q.aggregate(ctx, db, c, "SELECT EXISTS(SELECT 1"+q.buildFrom(c, 0)+")", &result)
+	if err != nil {
+		return false, fmt.Errorf("si.exists: %w", err)
+	}
+	return result, nil
+}
+
+// Sum will return the sum of `column` for all rows that matches the query.
+// The result is 0 if there are no rows.
+func (q *Q[T]) Sum(db DB, column string) (float64, error) {
+	return q.SumContext(context.Background(), db, column)
+}
+
+// SumContext is same as Sum, but with a context.
+func (q *Q[T]) SumContext(ctx context.Context, db DB, column string) (float64, error) {
+	return q.aggregateFunc(ctx, db, "SUM", column)
+}
+
+// Avg will return the average of `column` for all rows that matches the query.
+// The result is 0 if there are no rows.
+func (q *Q[T]) Avg(db DB, column string) (float64, error) {
+	return q.AvgContext(context.Background(), db, column)
+}
+
+// AvgContext is same as Avg, but with a context.
+func (q *Q[T]) AvgContext(ctx context.Context, db DB, column string) (float64, error) {
+	return q.aggregateFunc(ctx, db, "AVG", column)
+}
+
+// Min will return the smallest value of `column` for all rows that matches the query.
+// It is only for numeric columns, use `MinOf` for other columns.
+// The result is 0 if there are no rows.
+func (q *Q[T]) Min(db DB, column string) (float64, error) {
+	return q.MinContext(context.Background(), db, column)
+}
+
+// MinContext is same as Min, but with a context.
+func (q *Q[T]) MinContext(ctx context.Context, db DB, column string) (float64, error) {
+	return q.aggregateFunc(ctx, db, "MIN", column)
+}
+
+// Max will return the largest value of `column` for all rows that matches the query.
+// It is only for numeric columns, use `MaxOf` for other columns.
+// The result is 0 if there are no rows.
+func (q *Q[T]) Max(db DB, column string) (float64, error) {
+	return q.MaxContext(context.Background(), db, column)
+}
+
+// MaxContext is same as Max, but with a context.
+func (q *Q[T]) MaxContext(ctx context.Context, db DB, column string) (float64, error) {
+	return q.aggregateFunc(ctx, db, "MAX", column)
+}
+
+// MinOf will return the smallest value of `column` for all rows that matches the query, scanned into V.
+// Unlike `Min`, it can be used on any column, e.g. `si.MinOf[time.Time](q, db, "created_at")`.
+// The result is the zero value of V if there are no rows.
+func MinOf[V any, T Modeler](q *Q[T], db DB, column string) (V, error) {
+	return MinOfContext[V](context.Background(), q, db, column)
+}
+
+// MinOfContext is same as MinOf, but with a context.
+func MinOfContext[V any, T Modeler](ctx context.Context, q *Q[T], db DB, column string) (V, error) {
+	return aggregateOf[V](ctx, q, db, "MIN", column)
+}
+
+// MaxOf will return the largest value of `column` for all rows that matches the query, scanned into V.
+// Unlike `Max`, it can be used on any column, e.g. `si.MaxOf[time.Time](q, db, "created_at")`.
+// The result is the zero value of V if there are no rows.
+func MaxOf[V any, T Modeler](q *Q[T], db DB, column string) (V, error) {
+	return MaxOfContext[V](context.Background(), q, db, column)
+}
+
+// MaxOfContext is same as MaxOf, but with a context.
+func MaxOfContext[V any, T Modeler](ctx context.Context, q *Q[T], db DB, column string) (V, error) {
+	return aggregateOf[V](ctx, q, db, "MAX", column)
+}
+
+func (q *Q[T]) aggregateFunc(ctx context.Context, db DB, function, column string) (float64, error) {
+	return aggregateOf[float64](ctx, q, db, function, column)
+}
+
+func aggregateOf[V any, T Modeler](ctx context.Context, q *Q[T], db DB, function, column string) (V, error) {
+	var result *V
+	c := clientOf(db)
+	query := fmt.Sprintf("SELECT %s(%s)", function, column) + q.buildFrom(c, 0)
+	err := q.aggregate(ctx, db, c, query, &result)
+	if err != nil {
+		return *new(V), fmt.Errorf("si.%s: %w", strings.ToLower(function), err)
+	}
+	if result == nil {
+		return *new(V), nil
+	}
+	return *result, nil
+}
+
+func (q *Q[T]) aggregate(ctx context.Context, db DB, c *Client, query string, dest any) error {
+	rows, err := c.query(ctx, db, query, q.args...)
+	if err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rowsErr(rows); err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+		return errors.New("no result")
+	}
+	err = rows.Scan(dest)
+	if err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+	return nil
+}
@@ -2,11 +2,10 @@ package si
 
 import (
 	"reflect"
-
-	"github.com/google/uuid"
 )
 
 // BelongsTo is a relationship where the object in question (F) has a reference to the other object (T)
+// For a composite key, the reference is a comma separated list of fields, in the same order as the primary key of T.
 // Example:
 // | F    |     | T  |
 // |------|     |----|
@@ -15,43 +14,48 @@ import (
 func BelongsTo[F, T Modeler](model F, refFieldName, fieldName string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
 	fromType := reflect.TypeOf(new(F))
 	toType := reflect.TypeOf(new(T))
-	relationFieldName := getRelationFieldName(fromType, toType, fieldName, true)
-	refColumn := getColumnNameString(fromType, refFieldName)
-	idField := func(f F) uuid.UUID {
-		val := reflect.ValueOf(f)
-		field := val.FieldByName(relationFieldName)
-		return field.Interface().(uuid.UUID)
+	relationFieldNames := splitFieldNames(getRelationFieldName(fromType, toType, fieldName, true))
+	refColumns := columnsFor(fromType, refFieldName)
+	idField := func(f F) ([]any, bool) {
+		return fieldValues(f, relationFieldNames)
+	}
+
+	var get *Q[T]
+	if id, ok := idField(model); ok {
+		get = Query[T]()
+		get.filters = keyFilters(keyColumns[T](), id)
 	}
 
 	return &Relation[F, T]{
 		model:        model,
 		query:        Query[T](),
-		get:          Query[T]().Where("id", "=", idField(model)),
+		get:          get,
 		relationData: relationDataFunc,
 		relationType: belongsToConf[F, T]{
-			refColumn: refColumn,
-			idField:   idField,
+			refColumns: refColumns,
+			idField:    idField,
 		},
 	}
 }
 
 type belongsToConf[F, T Modeler] struct {
-	refColumn string
-	idField   func(F) uuid.UUID
+	refColumns []string
+	idField    func(F) ([]any, bool)
 }
 
-func (b belongsToConf[F, T]) collectID(f F) uuid.UUID {
+func (b belongsToConf[F, T]) collectID(f F) ([]any, bool) {
 	return b.idField(f)
 }
 
-func (b belongsToConf[F, T]) groupBy(t T) uuid.UUID {
-	return *t.GetModel().ID
+func (b belongsToConf[F, T]) groupBy(t T) []any {
+	id, _ := keyValues(t)
+	return id
 }
 
-func (b belongsToConf[F, T]) queryColumn() string {
-	return "id"
+func (b belongsToConf[F, T]) queryColumns() []string {
+	return keyColumns[T]()
 }
 
-func (b belongsToConf[F, T]) joinColumns() (string, string) {
-	return b.refColumn, "id"
+func (b belongsToConf[F, T]) joinColumns() ([]string, []string) {
+	return b.refColumns, keyColumns[T]()
 }
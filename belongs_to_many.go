@@ -0,0 +1,301 @@
+package si
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// BelongsToMany is a relationship where the objects are connected through a pivot table.
+// `fromKey` and `toKey` are the columns in the pivot table that references F and T.
+// Example:
+// | F  |     | Pivot   |     | T  |
+// |----|     |---------|     |----|
+// | ID | <-- | fromKey |     |    |
+// |    |     | toKey   | --> | ID |
+func BelongsToMany[F, T Modeler](model F, pivotTable, fromKey, toKey string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
+	toTable := (*new(T)).GetTable()
+
+	var get *Q[T]
+	if id, ok := keyValue(model); ok {
+		get = Query[T]().Where(toTable+"."+keyColumn[T](), "IN", subQuery(func(d Dialect, arg func(value any) string) string {
+			return fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s", d.Quote(toKey), d.Quote(pivotTable), d.Quote(fromKey), arg(id))
+		}))
+	}
+
+	return &Relation[F, T]{
+		model:        model,
+		query:        Query[T](),
+		get:          get,
+		relationData: relationDataFunc,
+		relationType: belongsToManyConf[F, T]{
+			pivotTable: pivotTable,
+			fromKey:    fromKey,
+			toKey:      toKey,
+		},
+	}
+}
+
+type belongsToManyConf[F, T Modeler] struct {
+	pivotTable string
+	fromKey    string
+	toKey      string
+}
+
+func (b belongsToManyConf[F, T]) collectID(f F) ([]any, bool) {
+	return keyValues(f)
+}
+
+func (b belongsToManyConf[F, T]) groupBy(t T) []any {
+	id, _ := keyValues(t)
+	return id
+}
+
+func (b belongsToManyConf[F, T]) queryColumns() []string {
+	return keyColumns[T]()
+}
+
+func (b belongsToManyConf[F, T]) joinColumns() ([]string, []string) {
+	return keyColumns[F](), keyColumns[T]()
+}
+
+// load will get the related objects joined with the pivot table, grouped and partitioned by the key of F in the pivot table.
+// The pivot table is joined as a sub query with only the two keys, with names that can not be the same as the columns of T.
+func (b belongsToManyConf[F, T]) load(ctx context.Context, db DB, query *Q[T], ids []any) (map[any][]T, error) {
+	d := clientOf(db).dialect
+	toTable := (*new(T)).GetTable()
+	pivot := &JoinConf{
+		JoinType: INNER,
+		Table:    fmt.Sprintf("(SELECT %s AS si_from, %s AS si_to FROM %s)", d.Quote(b.fromKey), d.Quote(b.toKey), d.Quote(b.pivotTable)),
+		Alias:    "si_pivot",
+		Condition: []filter{
+			{Column: "si_pivot.si_to", Operation: "=", Value: Raw(toTable + "." + keyColumn[T]()), Separator: "AND"},
+		},
+		withoutDeletedAt: true,
+	}
+	query.joins = append(query.joins, func(T) *JoinConf { return pivot })
+	query.filters = append(query.filters, filter{Column: "si_pivot.si_from", Operation: "IN", Value: ids, Separator: "AND"})
+	return loadGroups(ctx, db, query, "si_pivot.si_from", keyType[F]())
+}
+
+// pivots will return the pairs of from and to ids in the pivot table, for the given from ids.
+// The ids are scanned as the same type as the primary keys of F and T.
+func (b belongsToManyConf[F, T]) pivots(ctx context.Context, db DB, fromIDs []any) ([][2]any, error) {
+	if len(fromIDs) == 0 {
+		return nil, nil
+	}
+	c := clientOf(db)
+	d := c.dialect
+	var placeholders []string
+	var parameters []any
+	for _, id := range fromIDs {
+		parameters = append(parameters, id)
+		placeholders = append(placeholders, d.Placeholder(len(parameters)))
+	}
+	query := fmt.Sprintf(
+		"SELECT %s,%s FROM %s WHERE %s IN (%s)",
+		d.Quote(b.fromKey),
+		d.Quote(b.toKey),
+		d.Quote(b.pivotTable),
+		d.Quote(b.fromKey),
+		strings.Join(placeholders, ","),
+	)
+
+	rows, err := c.query(ctx, db, query, parameters...)
+	if err != nil {
+		return nil, fmt.Errorf("si.pivot: execute query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	fromType, toType := keyType[F](), keyType[T]()
+	var result [][2]any
+	for rows.Next() {
+		from, to := reflect.New(fromType), reflect.New(toType)
+		err = rows.Scan(from.Interface(), to.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("si.pivot: scan: %w", err)
+		}
+		result = append(result, [2]any{from.Elem().Interface(), to.Elem().Interface()})
+	}
+	if err := rowsErr(rows); err != nil {
+		return nil, fmt.Errorf("si.pivot: scan: %w", err)
+	}
+	return result, nil
+}
+
+func (b belongsToManyConf[F, T]) join(joinType JoinType, toTable string) *JoinConf {
+	fromTable := (*new(F)).GetTable()
+	return &JoinConf{
+		JoinType: joinType,
+		Table:    (*new(T)).GetTable(),
+		Condition: []filter{
+			{
+				Column:    fmt.Sprintf("%s.%s", b.pivotTable, b.toKey),
+				Operation: "=",
+				Value:     Raw(fmt.Sprintf("%s.%s", toTable, keyColumn[T]())),
+				Separator: "AND",
+			},
+		},
+		through: &JoinConf{
+			JoinType: joinType,
+			Table:    b.pivotTable,
+			Condition: []filter{
+				{
+					Column:    fmt.Sprintf("%s.%s", fromTable, keyColumn[F]()),
+					Operation: "=",
+					Value:     Raw(fmt.Sprintf("%s.%s", b.pivotTable, b.fromKey)),
+					Separator: "AND",
+				},
+			},
+			withoutDeletedAt: true,
+		},
+	}
+}
+
+// Attach will add rows in the pivot table, between the model and `ids`.
+// This only works on a BelongsToMany relation, and will not change the relation if it is already loaded.
+func (r *Relation[F, T]) Attach(db DB, ids ...any) error {
+	return r.AttachContext(context.Background(), db, ids...)
+}
+
+// AttachContext is same as Attach, but with a context.
+func (r *Relation[F, T]) AttachContext(ctx context.Context, db DB, ids ...any) error {
+	b, fromID, err := r.pivot()
+	if err != nil {
+		return fmt.Errorf("si.attach: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	c := clientOf(db)
+	d := c.dialect
+	var values []string
+	var parameters []any
+	for _, id := range ids {
+		parameters = append(parameters, fromID, id)
+		values = append(values, fmt.Sprintf("(%s,%s)", d.Placeholder(len(parameters)-1), d.Placeholder(len(parameters))))
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s,%s) VALUES %s",
+		d.Quote(b.pivotTable),
+		d.Quote(b.fromKey),
+		d.Quote(b.toKey),
+		strings.Join(values, ","),
+	)
+	_, err = c.exec(ctx, db, query, parameters...)
+	if err != nil {
+		return fmt.Errorf("si.attach: execute query: %w", err)
+	}
+	return nil
+}
+
+// Detach will remove the rows in the pivot table, between the model and `ids`.
+// If no `ids` are given, all rows for the model are removed.
+// This only works on a BelongsToMany relation, and will not change the relation if it is already loaded.
+func (r *Relation[F, T]) Detach(db DB, ids ...any) error {
+	return r.DetachContext(context.Background(), db, ids...)
+}
+
+// DetachContext is same as Detach, but with a context.
+func (r *Relation[F, T]) DetachContext(ctx context.Context, db DB, ids ...any) error {
+	b, fromID, err := r.pivot()
+	if err != nil {
+		return fmt.Errorf("si.detach: %w", err)
+	}
+
+	c := clientOf(db)
+	d := c.dialect
+	parameters := []any{fromID}
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE %s = %s",
+		d.Quote(b.pivotTable),
+		d.Quote(b.fromKey),
+		d.Placeholder(1),
+	)
+	if len(ids) > 0 {
+		var placeholders []string
+		for _, id := range ids {
+			parameters = append(parameters, id)
+			placeholders = append(placeholders, d.Placeholder(len(parameters)))
+		}
+		query += fmt.Sprintf(" AND %s IN (%s)", d.Quote(b.toKey), strings.Join(placeholders, ","))
+	}
+
+	_, err = c.exec(ctx, db, query, parameters...)
+	if err != nil {
+		return fmt.Errorf("si.detach: execute query: %w", err)
+	}
+	return nil
+}
+
+// Sync will change the rows in the pivot table, so the model is related to exactly `ids`.
+// The ids can be pointers, or strings for a key that can be parsed from text, e.g. a `uuid.UUID`.
+// Use a `Transaction` to make it atomic.
+// This only works on a BelongsToMany relation, and will not change the relation if it is already loaded.
+func (r *Relation[F, T]) Sync(db DB, ids []any) error {
+	return r.SyncContext(context.Background(), db, ids)
+}
+
+// SyncContext is same as Sync, but with a context.
+func (r *Relation[F, T]) SyncContext(ctx context.Context, db DB, ids []any) error {
+	b, fromID, err := r.pivot()
+	if err != nil {
+		return fmt.Errorf("si.sync: %w", err)
+	}
+	pivots, err := b.pivots(ctx, db, []any{fromID})
+	if err != nil {
+		return fmt.Errorf("si.sync: %w", err)
+	}
+
+	// The ids are compared with the scanned pivots, so they must be the same type, e.g. a `uuid.UUID` and not a string.
+	toType := keyType[T]()
+	var keys []any
+	for _, id := range ids {
+		key, err := keyOf(id, toType)
+		if err != nil {
+			return fmt.Errorf("si.sync: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	var existing, detach, attach []any
+	for _, p := range pivots {
+		existing = append(existing, p[1])
+		if !slices.Contains(keys, p[1]) {
+			detach = append(detach, p[1])
+		}
+	}
+	for _, id := range keys {
+		if !slices.Contains(existing, id) && !slices.Contains(attach, id) {
+			attach = append(attach, id)
+		}
+	}
+
+	if len(detach) > 0 {
+		err = r.DetachContext(ctx, db, detach...)
+		if err != nil {
+			return fmt.Errorf("si.sync: %w", err)
+		}
+	}
+	err = r.AttachContext(ctx, db, attach...)
+	if err != nil {
+		return fmt.Errorf("si.sync: %w", err)
+	}
+	return nil
+}
+
+func (r *Relation[F, T]) pivot() (belongsToManyConf[F, T], any, error) {
+	b, ok := r.relationType.(belongsToManyConf[F, T])
+	if !ok {
+		return b, nil, errors.New("the relation is not a BelongsToMany")
+	}
+	id, ok := keyValue(r.model)
+	if !ok {
+		return b, nil, errors.New("the model does not have an id")
+	}
+	return b, id, nil
+}
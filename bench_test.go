@@ -0,0 +1,124 @@
+package si_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/derivatan/si"
+	"github.com/google/uuid"
+)
+
+// The benchmarks and tests use a fake database, so they only test si, and need no driver.
+
+type benchArtist struct {
+	si.Model
+	Name  string
+	Plays int
+}
+
+func (a benchArtist) GetModel() si.Model { return a.Model }
+func (a benchArtist) GetTable() string   { return "artists" }
+
+// fakeDB will return `rows` rows for each query, and remember the queries and their arguments.
+// If `err` is set, it is returned from `Err` on the rows, as if the reading of the rows failed.
+type fakeDB struct {
+	rows    int
+	err     error
+	dialect si.Dialect
+	queries []string
+	args    [][]any
+	lastID  int64
+}
+
+func (db *fakeDB) Dialect() si.Dialect {
+	return db.dialect
+}
+
+func (db *fakeDB) Query(query string, args ...any) (si.Rows, error) {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+	return &fakeRows{left: db.rows, err: db.err, id: uuid.New(), now: time.Now()}, nil
+}
+
+func (db *fakeDB) Exec(query string, args ...any) (any, error) {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+	db.lastID++
+	return fakeResult(db.lastID), nil
+}
+
+// fakeResult is the result of `Exec`, like `sql.Result`.
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type fakeRows struct {
+	left int
+	err  error
+	id   uuid.UUID
+	now  time.Time
+}
+
+func (r *fakeRows) Next() bool {
+	r.left--
+	return r.left >= 0
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for _, d := range dest {
+		switch d := d.(type) {
+		case **uuid.UUID:
+			*d = &r.id
+		case *time.Time:
+			*d = r.now
+		case **time.Time:
+			*d = nil
+		case *string:
+			*d = "name"
+		case *int:
+			*d = r.left
+		case **int64:
+			id := int64(r.left + 1)
+			*d = &id
+		}
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func BenchmarkGet10k(b *testing.B) {
+	db := &fakeDB{rows: 10000}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result, err := si.Query[benchArtist]().Get(db)
+		if err != nil || len(result) != 10000 {
+			b.Fatal(err, len(result))
+		}
+	}
+}
+
+func BenchmarkEach10k(b *testing.B) {
+	db := &fakeDB{rows: 10000}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := si.Query[benchArtist]().Each(db, func(a benchArtist) error {
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
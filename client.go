@@ -0,0 +1,211 @@
+package si
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Client is a configuration of si.
+// A client is used by binding it to a `DB` with `Client.DB`, and then use that `DB` as usual.
+// All functions that are given a `DB` without a client will use the default client, which is configured with `SetLogger`, `UseDeletedAt`, `SetIDGenerator` and `SetDefault`.
+type Client struct {
+	logger       func(a ...any)
+	useDeletedAt bool
+	dialect      Dialect
+	queryHooks   []QueryHook
+	idGenerator  IDGenerator
+}
+
+// QueryHook is called after every query that is executed by si.
+type QueryHook func(ctx context.Context, query string, args []any, duration time.Duration, err error)
+
+// Option is a configuration for `New`.
+type Option func(c *Client)
+
+// WithLogger will set a logger function for debugging all queries.
+func WithLogger(f func(a ...any)) Option {
+	return func(c *Client) {
+		c.logger = f
+	}
+}
+
+// WithDeletedAt can disable or enable the usage of deleted_at in query generations.
+func WithDeletedAt(enabled bool) Option {
+	return func(c *Client) {
+		c.useDeletedAt = enabled
+	}
+}
+
+// WithDialect will set the dialect, for the `DB`s that does not have their own.
+func WithDialect(d Dialect) Option {
+	return func(c *Client) {
+		c.dialect = d
+	}
+}
+
+// WithQueryHook will add a hook that is called after every query.
+func WithQueryHook(h QueryHook) Option {
+	return func(c *Client) {
+		c.queryHooks = append(c.queryHooks, h)
+	}
+}
+
+// WithIDGenerator will set the generator for the ids of inserted models that does not have one.
+// Without a generator, the id is set by the database, or is a random uuid if the dialect can not return it.
+func WithIDGenerator(g IDGenerator) Option {
+	return func(c *Client) {
+		c.idGenerator = g
+	}
+}
+
+// New will create a new client.
+func New(opts ...Option) *Client {
+	c := &Client{
+		dialect: Postgres,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var (
+	defaultClient   atomic.Pointer[Client]
+	defaultClientMu sync.Mutex
+)
+
+func init() {
+	defaultClient.Store(New())
+}
+
+// SetDefault will replace the default client.
+func SetDefault(c *Client) {
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+	defaultClient.Store(c)
+}
+
+// updateDefault will change a copy of the default client, and then replace it.
+func updateDefault(f func(c *Client)) {
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+	c := *defaultClient.Load()
+	f(&c)
+	defaultClient.Store(&c)
+}
+
+// DB will bind the client to `db`. All queries executed with the returned `DB` will use this client.
+func (c *Client) DB(db DB) DB {
+	return &clientDB{db: db, c: c}
+}
+
+// clientOf will return the client bound to `db`, or the default client.
+// If `db` has its own dialect, that is used instead of the one on the client.
+func clientOf(db DB) *Client {
+	c := defaultClient.Load()
+	if cdb, ok := db.(interface{ client() *Client }); ok {
+		c = cdb.client()
+	}
+	if ddb, ok := db.(DialectDB); ok {
+		if d := ddb.Dialect(); d != nil && d != c.dialect {
+			cp := *c
+			cp.dialect = d
+			c = &cp
+		}
+	}
+	return c
+}
+
+func (c *Client) log(s ...any) {
+	if c.logger != nil {
+		c.logger(s)
+	}
+}
+
+func (c *Client) query(ctx context.Context, db DB, query string, args ...any) (Rows, error) {
+	c.log(query, args)
+	start := time.Now()
+	rows, err := queryContext(ctx, db, query, args...)
+	c.afterQuery(ctx, query, args, start, err)
+	return rows, err
+}
+
+func (c *Client) exec(ctx context.Context, db DB, query string, args ...any) (any, error) {
+	c.log(query, args)
+	start := time.Now()
+	result, err := execContext(ctx, db, query, args...)
+	c.afterQuery(ctx, query, args, start, err)
+	return result, err
+}
+
+func (c *Client) afterQuery(ctx context.Context, query string, args []any, start time.Time, err error) {
+	if len(c.queryHooks) == 0 {
+		return
+	}
+	duration := time.Since(start)
+	for _, hook := range c.queryHooks {
+		hook(ctx, query, args, duration, err)
+	}
+}
+
+// clientDB is a `DB` with a client bound to it.
+type clientDB struct {
+	db DB
+	c  *Client
+}
+
+func (cdb *clientDB) client() *Client {
+	return cdb.c
+}
+
+func (cdb *clientDB) Query(query string, args ...any) (Rows, error) {
+	return cdb.db.Query(query, args...)
+}
+
+func (cdb *clientDB) Exec(query string, args ...any) (any, error) {
+	return cdb.db.Exec(query, args...)
+}
+
+func (cdb *clientDB) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return queryContext(ctx, cdb.db, query, args...)
+}
+
+func (cdb *clientDB) ExecContext(ctx context.Context, query string, args ...any) (any, error) {
+	return execContext(ctx, cdb.db, query, args...)
+}
+
+func (cdb *clientDB) Dialect() Dialect {
+	if ddb, ok := cdb.db.(DialectDB); ok && ddb.Dialect() != nil {
+		return ddb.Dialect()
+	}
+	return cdb.c.dialect
+}
+
+func (cdb *clientDB) BeginTx(ctx context.Context) (Tx, error) {
+	beginner, ok := cdb.db.(TxBeginner)
+	if !ok {
+		return nil, errors.New("the bound db can not begin a transaction")
+	}
+	tx, err := beginner.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &clientTx{clientDB: clientDB{db: tx, c: cdb.c}, tx: tx}, nil
+}
+
+// clientTx is a `Tx` with a client bound to it.
+type clientTx struct {
+	clientDB
+	tx Tx
+}
+
+func (t *clientTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *clientTx) Rollback() error {
+	return t.tx.Rollback()
+}
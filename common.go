@@ -1,10 +1,14 @@
 package si
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +19,9 @@ type Model struct {
 	CreatedAt time.Time  `si:"created_at" json:"createdAt"`
 	UpdatedAt *time.Time `si:"updated_at" json:"updatedAt"`
 	DeletedAt *time.Time `si:"deleted_at" json:"deletedAt"`
+
+	// snapshot is a pointer to a copy of the model when it was loaded or saved, for `Changes`.
+	snapshot any
 }
 
 type Modeler interface {
@@ -22,35 +29,77 @@ type Modeler interface {
 	GetTable() string
 }
 
-type ResourceNotFoundError struct{}
+var (
+	// ErrNotFound matches a ResourceNotFoundError with `errors.Is`.
+	ErrNotFound = errors.New("resource not found")
+	// ErrMultipleResults matches a MultipleResultsError with `errors.Is`.
+	ErrMultipleResults = errors.New("multiple results")
+)
 
-func (ResourceNotFoundError) Error() string {
-	return "Resource not found"
+// ResourceNotFoundError is returned when an object is not found.
+// `Table` and `Filters` describe the query, when it is known.
+type ResourceNotFoundError struct {
+	Table   string
+	Filters string
+}
+
+func (e ResourceNotFoundError) Error() string {
+	if e.Table == "" {
+		return "Resource not found"
+	}
+	if e.Filters == "" {
+		return fmt.Sprintf("Resource not found in '%s'", e.Table)
+	}
+	return fmt.Sprintf("Resource not found in '%s' where %s", e.Table, e.Filters)
+}
+
+// Is will match ErrNotFound, and any ResourceNotFoundError, e.g. `ResourceNotFound()`.
+func (ResourceNotFoundError) Is(target error) bool {
+	_, ok := target.(ResourceNotFoundError)
+	return ok || target == ErrNotFound
 }
 
 func ResourceNotFound() error {
 	return ResourceNotFoundError{}
 }
 
-var config secretIngredientConfig
+// MultipleResultsError is returned by `Find` when more than one object matches the query.
+type MultipleResultsError struct {
+	Table   string
+	Filters string
+	Count   int64
+}
 
-type secretIngredientConfig struct {
-	logger       func(a ...any)
-	useDeletedAt bool
+func (e MultipleResultsError) Error() string {
+	if e.Filters == "" {
+		return fmt.Sprintf("Expected one result in '%s', but found %d", e.Table, e.Count)
+	}
+	return fmt.Sprintf("Expected one result in '%s' where %s, but found %d", e.Table, e.Filters, e.Count)
+}
+
+// Is will match ErrMultipleResults, and any MultipleResultsError.
+func (MultipleResultsError) Is(target error) bool {
+	_, ok := target.(MultipleResultsError)
+	return ok || target == ErrMultipleResults
 }
 
 type ModelConfig[T Modeler] struct {
 	Table string
 }
 
-// SetLogger will set a logger function for debugging all queries.
+// SetLogger will set a logger function for debugging all queries, on the default client.
 func SetLogger(f func(a ...any)) {
-	config.logger = f
+	updateDefault(WithLogger(f))
+}
+
+// SetIDGenerator will set the generator for the ids of inserted models, on the default client.
+func SetIDGenerator(g IDGenerator) {
+	updateDefault(WithIDGenerator(g))
 }
 
-// UseDeletedAt can disable or enable the usage of deleted_at in query generations.
+// UseDeletedAt can disable or enable the usage of deleted_at in query generations, on the default client.
 func UseDeletedAt(enabled bool) {
-	config.useDeletedAt = enabled
+	updateDefault(WithDeletedAt(enabled))
 }
 
 // Query will start a query.
@@ -71,56 +120,138 @@ func Set[T Modeler]() *S[T] {
 }
 
 // Save a model to the database.
-// If the does not have an ID, it will be inserted into the database, and the ID will be set on the model.
-// If the model has an ID, the model will be updated.
+// If the model does not have a primary key, it will be inserted into the database, and the key will be set on the model.
+// If the model has a primary key, the model will be updated. Use Insert for a new model with a given or composite key.
+// A model that is loaded or saved before only updates the changed columns, and nothing if there are no changes.
 func Save[T Modeler](db DB, m *T) error {
-	return save[T](db, m, nil)
+	return SaveContext[T](context.Background(), db, m)
+}
+
+// SaveContext is same as Save, but with a context.
+func SaveContext[T Modeler](ctx context.Context, db DB, m *T) error {
+	return save[T](ctx, db, m, nil)
 }
 
 // Insert will create a new row in the database.
 // This can be used to force an insert when we set a given ID instead of generating a new one.
 func Insert[T Modeler](db DB, m *T) error {
-	return insert[T](db, m)
+	return InsertContext[T](context.Background(), db, m)
+}
+
+// InsertContext is same as Insert, but with a context.
+func InsertContext[T Modeler](ctx context.Context, db DB, m *T) error {
+	return insert[T](ctx, db, m)
+}
+
+// InsertMany will insert all models with as few queries as possible, and set the IDs on the models.
+// `CreatedAt` and `UpdatedAt` are set on all models.
+// The queries are split to stay within the parameter limit of the dialect, use a `Transaction` to make it atomic.
+func InsertMany[T Modeler](db DB, models []T) error {
+	return InsertManyContext[T](context.Background(), db, models)
+}
+
+// InsertManyContext is same as InsertMany, but with a context.
+func InsertManyContext[T Modeler](ctx context.Context, db DB, models []T) error {
+	return insertMany[T](ctx, db, pointers(models), nil, "insertMany")
+}
+
+// Upsert will insert the model, or update it if there is a conflict on `conflictColumns`.
+// Only `updateColumns` and `UpdatedAt` are updated, or all columns if `updateColumns` is nil.
+// The ID of the inserted or updated row is set on the model.
+func Upsert[T Modeler](db DB, m *T, conflictColumns, updateColumns []string) error {
+	return UpsertContext[T](context.Background(), db, m, conflictColumns, updateColumns)
+}
+
+// UpsertContext is same as Upsert, but with a context.
+func UpsertContext[T Modeler](ctx context.Context, db DB, m *T, conflictColumns, updateColumns []string) error {
+	return insertMany[T](ctx, db, []*T{m}, newUpsertConf[T](conflictColumns, updateColumns), "upsert")
+}
+
+// UpsertMany is same as Upsert, but for many models with as few queries as possible, like InsertMany.
+// The same conflicting values must not occur more than once in `models`.
+func UpsertMany[T Modeler](db DB, models []T, conflictColumns, updateColumns []string) error {
+	return UpsertManyContext[T](context.Background(), db, models, conflictColumns, updateColumns)
+}
+
+// UpsertManyContext is same as UpsertMany, but with a context.
+func UpsertManyContext[T Modeler](ctx context.Context, db DB, models []T, conflictColumns, updateColumns []string) error {
+	return insertMany[T](ctx, db, pointers(models), newUpsertConf[T](conflictColumns, updateColumns), "upsertMany")
 }
 
 // Update will update a model, but only the columns listed in `fields`.
 // If you want to update the whole model, use Save
 func Update[T Modeler](db DB, m *T, fields []string) error {
-	if (*m).GetModel().ID == nil {
-		return ResourceNotFound()
+	return UpdateContext[T](context.Background(), db, m, fields)
+}
+
+// UpdateContext is same as Update, but with a context.
+func UpdateContext[T Modeler](ctx context.Context, db DB, m *T, fields []string) error {
+	if _, ok := keyValues(*m); !ok {
+		return ResourceNotFoundError{Table: (*m).GetTable()}
 	}
-	return save[T](db, m, fields)
+	return save[T](ctx, db, m, fields)
 }
 
 // Delete will 'soft-delete' a model from the database.
-func Delete[T Modeler](db DB, id uuid.UUID) error {
-	return delete_[T](db, id)
+// `id` is the value of the primary key, or one value for each column of a composite key.
+func Delete[T Modeler](db DB, id ...any) error {
+	return DeleteContext[T](context.Background(), db, id...)
+}
+
+// DeleteContext is same as Delete, but with a context.
+func DeleteContext[T Modeler](ctx context.Context, db DB, id ...any) error {
+	return delete_[T](ctx, db, id)
 }
 
 // DeleteHard will 'hard-delete' a model from the database.
-func DeleteHard[T Modeler](db DB, id uuid.UUID) error {
-	return deleteHard[T](db, id)
+// `id` is the value of the primary key, or one value for each column of a composite key.
+func DeleteHard[T Modeler](db DB, id ...any) error {
+	return DeleteHardContext[T](context.Background(), db, id...)
 }
 
-func log(s ...any) {
-	if config.logger != nil {
-		config.logger(s)
+// DeleteHardContext is same as DeleteHard, but with a context.
+func DeleteHardContext[T Modeler](ctx context.Context, db DB, id ...any) error {
+	return deleteHard[T](ctx, db, id)
+}
+
+func pointers[T any](list []T) []*T {
+	result := make([]*T, len(list))
+	for i := range list {
+		result[i] = &list[i]
 	}
+	return result
 }
 
 type typeInfo struct {
 	Columns []string
 	Names   []string
 	Values  []any
+	// Key is the indexes of the primary key columns.
+	Key []int
 }
 
-func getTypeInfo(obj any) typeInfo {
-	result := typeInfo{}
-	refType := reflect.TypeOf(obj).Elem()
-	refVal := reflect.ValueOf(obj).Elem()
-	for i := 0; i < refVal.NumField(); i++ {
+// modelInfo is the reflection metadata of a model type, which is only computed once per type.
+// The slices are shared and must not be modified.
+type modelInfo struct {
+	Columns []string
+	Names   []string
+	indexes [][]int
+	// key is the indexes in `Columns` of the primary key, it is empty if the model does not have all of the columns.
+	key []int
+	// rules is the validation rules of each column.
+	rules [][]rule
+}
+
+var modelInfos sync.Map // reflect.Type -> *modelInfo
+
+func getModelInfo(refType reflect.Type) *modelInfo {
+	if info, ok := modelInfos.Load(refType); ok {
+		return info.(*modelInfo)
+	}
+
+	info := &modelInfo{}
+	for i := 0; i < refType.NumField(); i++ {
 		fieldType := refType.Field(i)
-		fieldVal := refVal.Field(i)
 		if !fieldType.IsExported() {
 			continue
 		}
@@ -128,43 +259,113 @@ func getTypeInfo(obj any) typeInfo {
 			continue
 		}
 
-		if i == 0 {
+		if fieldType.Anonymous && fieldType.Type.Kind() == reflect.Struct {
 			for j := 0; j < fieldType.Type.NumField(); j++ {
-				modelType := fieldType.Type.Field(j)
-				modelVal := fieldVal.Field(j)
-				result = appendResult(modelType, modelVal, result)
+				if fieldType.Type.Field(j).IsExported() {
+					info.add(fieldType.Type.Field(j), []int{i, j})
+				}
 			}
 			continue
 		}
-		result = appendResult(fieldType, fieldVal, result)
+		info.add(fieldType, []int{i})
+	}
+
+	keyColumns := []string{"id"}
+	if pk, ok := reflect.Zero(refType).Interface().(PrimaryKeyer); ok {
+		keyColumns = pk.PrimaryKey()
+	}
+	for _, column := range keyColumns {
+		index := slices.Index(info.Columns, column)
+		if index < 0 {
+			info.key = nil
+			break
+		}
+		info.key = append(info.key, index)
+	}
+
+	actual, _ := modelInfos.LoadOrStore(refType, info)
+	return actual.(*modelInfo)
+}
+
+func (mi *modelInfo) add(field reflect.StructField, index []int) {
+	mi.Columns = append(mi.Columns, getColumnName(field))
+	mi.Names = append(mi.Names, field.Name)
+	mi.indexes = append(mi.indexes, index)
+	mi.rules = append(mi.rules, parseRules(field))
+}
+
+// setTime will set the time on the `column` field of the model that `refVal` points to, if there is one.
+func (mi *modelInfo) setTime(refVal reflect.Value, column string, t time.Time) {
+	index := slices.Index(mi.Columns, column)
+	if index < 0 {
+		return
+	}
+	field := refVal.Elem().FieldByIndex(mi.indexes[index])
+	switch field.Interface().(type) {
+	case time.Time:
+		field.Set(reflect.ValueOf(t))
+	case *time.Time:
+		field.Set(reflect.ValueOf(&t))
 	}
+}
 
+// values will return pointers to all the fields of the model that `refVal` points to.
+// This is the scan plan used when reading rows, and the values used when writing.
+func (mi *modelInfo) values(refVal reflect.Value) []any {
+	elem := refVal.Elem()
+	result := make([]any, len(mi.indexes))
+	for i, index := range mi.indexes {
+		result[i] = elem.FieldByIndex(index).Addr().Interface()
+	}
 	return result
 }
 
-func appendResult(t reflect.StructField, v reflect.Value, ti typeInfo) typeInfo {
-	column := getColumnName(t)
-	value := v.Addr().Interface()
-	ti.Columns = append(ti.Columns, column)
-	ti.Names = append(ti.Names, t.Name)
-	ti.Values = append(ti.Values, value)
-	return ti
+func getTypeInfo(obj any) typeInfo {
+	refVal := reflect.ValueOf(obj)
+	info := getModelInfo(refVal.Type().Elem())
+	return typeInfo{
+		Columns: info.Columns,
+		Names:   info.Names,
+		Values:  info.values(refVal),
+		Key:     info.key,
+	}
 }
 
+var (
+	matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
+	matchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
+)
+
 func toSnakeCase(str string) string {
-	var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
-	var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 	snake := matchFirstCap.ReplaceAllString(str, "${1}_${2}")
 	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
 	return strings.ToLower(snake)
 }
 
+type fieldKey struct {
+	f         reflect.Type
+	t         reflect.Type
+	name      string
+	fieldOnTo bool
+}
+
+var (
+	columnNames        sync.Map // fieldKey -> string
+	relationFieldNames sync.Map // fieldKey -> string
+)
+
 func getColumnNameString(t reflect.Type, fieldName string) string {
+	key := fieldKey{f: t, name: fieldName}
+	if column, ok := columnNames.Load(key); ok {
+		return column.(string)
+	}
 	field, ok := t.Elem().FieldByName(fieldName)
 	if !ok {
 		panic(fmt.Sprintf("'%s' is not a field on model '%s'", fieldName, t.Elem().Name()))
 	}
-	return getColumnName(field)
+	column := getColumnName(field)
+	columnNames.Store(key, column)
+	return column
 }
 
 func getColumnName(field reflect.StructField) string {
@@ -175,6 +376,10 @@ func getColumnName(field reflect.StructField) string {
 }
 
 func getRelationFieldName(f reflect.Type, t reflect.Type, fieldName string, fieldOnTo bool) string {
+	key := fieldKey{f: f, t: t, name: fieldName, fieldOnTo: fieldOnTo}
+	if name, ok := relationFieldNames.Load(key); ok {
+		return name.(string)
+	}
 	field, ok := f.Elem().FieldByName(fieldName)
 	if !ok {
 		panic(fmt.Sprintf("'%s' is not a field on model '%s'", fieldName, t.Elem().Name()))
@@ -186,7 +391,8 @@ func getRelationFieldName(f reflect.Type, t reflect.Type, fieldName string, fiel
 			s = t.String()
 		}
 		result := strings.Split(s, ".")[1] + "ID"
-		return strings.ToUpper(result[:1]) + result[1:]
+		referenceField = strings.ToUpper(result[:1]) + result[1:]
 	}
+	relationFieldNames.Store(key, referenceField)
 	return referenceField
 }
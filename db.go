@@ -1,13 +1,49 @@
 package si
 
+import "context"
+
 // DB is based on `sql.DB`, but generalized with an implementation independent version of `Rows`.
+// The result of `Exec` must have a `LastInsertId() (int64, error)` method, like `sql.Result`,
+// since it is used to set integer keys when the dialect can not return them, e.g. MySQL.
 type DB interface {
 	Query(query string, args ...any) (Rows, error)
 	Exec(query string, args ...any) (any, error)
 }
 
+// ContextDB is a `DB` that can also carry a `context.Context` to the database.
+// If the `DB` given to an executor implements this, the context versions are used.
+type ContextDB interface {
+	DB
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (any, error)
+}
+
+// Rows can also implement `Err() error`, like `sql.Rows`, which is checked after the last row.
+// Without it, an error while reading the rows, e.g. a cancelled context, can not be told apart from the end of the rows.
 type Rows interface {
 	Next() bool
 	Scan(dest ...any) error
 	Close() error
 }
+
+// rowsErr will return the error that ended the iteration of `rows`, if it implements `Err() error`.
+func rowsErr(rows Rows) error {
+	if r, ok := rows.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+func queryContext(ctx context.Context, db DB, query string, args ...any) (Rows, error) {
+	if cdb, ok := db.(ContextDB); ok {
+		return cdb.QueryContext(ctx, query, args...)
+	}
+	return db.Query(query, args...)
+}
+
+func execContext(ctx context.Context, db DB, query string, args ...any) (any, error) {
+	if cdb, ok := db.(ContextDB); ok {
+		return cdb.ExecContext(ctx, query, args...)
+	}
+	return db.Exec(query, args...)
+}
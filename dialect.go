@@ -0,0 +1,210 @@
+package si
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect is the parts of the SQL syntax that differ between databases.
+type Dialect interface {
+	// Placeholder is the parameter placeholder for the n:th argument, starting at 1.
+	Placeholder(n int) string
+	// Quote will quote an identifier, like a table or a column. `table.column` is quoted as two identifiers.
+	Quote(identifier string) string
+	// Returning tells if the database supports `INSERT ... RETURNING`.
+	// If not, the ID is generated before the model is inserted.
+	Returning() bool
+	// LimitOffset is the clause to limit the result, including a leading space. Zero means no limit or no offset.
+	LimitOffset(limit, offset int) string
+	// Now is the expression for the current timestamp.
+	Now() string
+	// Operator will translate a comparison operator to one that the database supports, e.g. `ILIKE`.
+	Operator(op string) string
+	// MaxParameters is the maximum number of parameters in one query.
+	MaxParameters() int
+	// Upsert is the clause to add to an insert, to update `updateColumns` when the row already exists.
+	Upsert(conflictColumns, updateColumns []string) string
+}
+
+// DialectDB is a `DB` that knows which dialect to use. If a `DB` does not implement this, the dialect of the client is used.
+type DialectDB interface {
+	Dialect() Dialect
+}
+
+var (
+	Postgres Dialect = postgresDialect{}
+	MySQL    Dialect = mysqlDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+func quote(identifier, q string) string {
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = q + strings.ReplaceAll(part, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
+
+// Postgres
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (postgresDialect) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (postgresDialect) Returning() bool {
+	return true
+}
+
+func (postgresDialect) LimitOffset(limit, offset int) string {
+	var result string
+	if limit > 0 {
+		result += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		result += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return result
+}
+
+func (postgresDialect) Now() string {
+	return "now()"
+}
+
+func (postgresDialect) Operator(op string) string {
+	return op
+}
+
+func (postgresDialect) MaxParameters() int {
+	return 65535
+}
+
+func (d postgresDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return onConflict(d, conflictColumns, updateColumns)
+}
+
+// MySQL
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) Quote(identifier string) string {
+	return quote(identifier, "`")
+}
+
+func (mysqlDialect) Returning() bool {
+	return false
+}
+
+func (mysqlDialect) LimitOffset(limit, offset int) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+	if limit <= 0 {
+		// MySQL can not have an offset without a limit.
+		return fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", offset)
+	}
+	if offset <= 0 {
+		return fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (mysqlDialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (mysqlDialect) Operator(op string) string {
+	return caseInsensitiveLike(op)
+}
+
+func (mysqlDialect) MaxParameters() int {
+	return 65535
+}
+
+// Upsert will update on any duplicate key, since MySQL does not support a conflict target.
+func (d mysqlDialect) Upsert(_, updateColumns []string) string {
+	var sets []string
+	for _, column := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%s=VALUES(%s)", d.Quote(column), d.Quote(column)))
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+}
+
+// SQLite
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (sqliteDialect) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (sqliteDialect) Returning() bool {
+	return true
+}
+
+func (sqliteDialect) LimitOffset(limit, offset int) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+	if limit <= 0 {
+		// SQLite can not have an offset without a limit.
+		return fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	}
+	if offset <= 0 {
+		return fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (sqliteDialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (sqliteDialect) Operator(op string) string {
+	return caseInsensitiveLike(op)
+}
+
+func (sqliteDialect) MaxParameters() int {
+	return 32766
+}
+
+func (d sqliteDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return onConflict(d, conflictColumns, updateColumns)
+}
+
+// onConflict is the upsert clause for both Postgres and SQLite.
+func onConflict(d Dialect, conflictColumns, updateColumns []string) string {
+	var conflicts []string
+	for _, column := range conflictColumns {
+		conflicts = append(conflicts, d.Quote(column))
+	}
+	var sets []string
+	for _, column := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%s=EXCLUDED.%s", d.Quote(column), d.Quote(column)))
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflicts, ","), strings.Join(sets, ","))
+}
+
+// caseInsensitiveLike will replace `ILIKE` with `LIKE`, which is already case-insensitive in MySQL and SQLite.
+func caseInsensitiveLike(op string) string {
+	switch strings.ToUpper(op) {
+	case "ILIKE":
+		return "LIKE"
+	case "NOT ILIKE":
+		return "NOT LIKE"
+	}
+	return op
+}
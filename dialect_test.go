@@ -0,0 +1,163 @@
+package si_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/derivatan/si"
+)
+
+type query struct {
+	sql  string
+	args []any
+}
+
+// now is the value of the timestamps that are set on insert, in the arguments from `values`.
+const now = "now"
+
+// values will return the values of the arguments without any pointers, and `now` for the timestamps.
+func values(args []any) []any {
+	var result []any
+	for _, arg := range args {
+		v := reflect.ValueOf(arg)
+		for v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Pointer {
+			result = append(result, nil)
+			continue
+		}
+		if t, ok := v.Interface().(time.Time); ok && !t.IsZero() {
+			result = append(result, now)
+			continue
+		}
+		result = append(result, v.Interface())
+	}
+	return result
+}
+
+func TestDialects(t *testing.T) {
+	var zero time.Time
+	tests := []struct {
+		name string
+		rows int
+		run  func(db si.DB) error
+		want map[si.Dialect][]query
+	}{
+		{
+			name: "select",
+			run: func(db si.DB) error {
+				_, err := si.Query[benchArtist]().Where("name", "ILIKE", "a%").Where("plays", ">", 3).OrderBy("name", true).Skip(20).Take(10).Get(db)
+				return err
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`SELECT "artists"."id","artists"."created_at","artists"."updated_at","artists"."deleted_at","artists"."name","artists"."plays" FROM "artists" WHERE name ILIKE $1 AND plays > $2 ORDER BY name asc  LIMIT 10 OFFSET 20`, []any{"a%", 3}}},
+				si.MySQL:    {{"SELECT `artists`.`id`,`artists`.`created_at`,`artists`.`updated_at`,`artists`.`deleted_at`,`artists`.`name`,`artists`.`plays` FROM `artists` WHERE name LIKE ? AND plays > ? ORDER BY name asc  LIMIT 10 OFFSET 20", []any{"a%", 3}}},
+				si.SQLite:   {{`SELECT "artists"."id","artists"."created_at","artists"."updated_at","artists"."deleted_at","artists"."name","artists"."plays" FROM "artists" WHERE name LIKE ? AND plays > ? ORDER BY name asc  LIMIT 10 OFFSET 20`, []any{"a%", 3}}},
+			},
+		},
+		{
+			name: "offset without limit",
+			run: func(db si.DB) error {
+				_, err := si.Query[benchArtist]().Skip(5).Get(db)
+				return err
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`SELECT "artists"."id","artists"."created_at","artists"."updated_at","artists"."deleted_at","artists"."name","artists"."plays" FROM "artists" OFFSET 5`, nil}},
+				si.MySQL:    {{"SELECT `artists`.`id`,`artists`.`created_at`,`artists`.`updated_at`,`artists`.`deleted_at`,`artists`.`name`,`artists`.`plays` FROM `artists` LIMIT 18446744073709551615 OFFSET 5", nil}},
+				si.SQLite:   {{`SELECT "artists"."id","artists"."created_at","artists"."updated_at","artists"."deleted_at","artists"."name","artists"."plays" FROM "artists" LIMIT -1 OFFSET 5`, nil}},
+			},
+		},
+		{
+			name: "insert",
+			rows: 1,
+			run: func(db si.DB) error {
+				return si.Insert(db, &counter{Name: "a"})
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES ($1,$2,$3,$4) RETURNING "id"`, []any{zero, nil, nil, "a"}}},
+				si.MySQL:    {{"INSERT INTO `counters` (`created_at`,`updated_at`,`deleted_at`,`name`) VALUES (?,?,?,?)", []any{zero, nil, nil, "a"}}},
+				si.SQLite:   {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES (?,?,?,?) RETURNING "id"`, []any{zero, nil, nil, "a"}}},
+			},
+		},
+		{
+			name: "insert many",
+			rows: 2,
+			run: func(db si.DB) error {
+				return si.InsertMany(db, []counter{{Name: "a"}, {Name: "b"}})
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES ($1,$2,$3,$4),($5,$6,$7,$8) RETURNING "id"`, []any{now, now, nil, "a", now, now, nil, "b"}}},
+				si.MySQL: {
+					{"INSERT INTO `counters` (`created_at`,`updated_at`,`deleted_at`,`name`) VALUES (?,?,?,?)", []any{now, now, nil, "a"}},
+					{"INSERT INTO `counters` (`created_at`,`updated_at`,`deleted_at`,`name`) VALUES (?,?,?,?)", []any{now, now, nil, "b"}},
+				},
+				si.SQLite: {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES (?,?,?,?),(?,?,?,?) RETURNING "id"`, []any{now, now, nil, "a", now, now, nil, "b"}}},
+			},
+		},
+		{
+			name: "upsert",
+			rows: 1,
+			run: func(db si.DB) error {
+				return si.Upsert(db, &counter{Name: "a"}, []string{"name"}, []string{"name", "updated_at"})
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES ($1,$2,$3,$4) ON CONFLICT ("name") DO UPDATE SET "name"=EXCLUDED."name","updated_at"=EXCLUDED."updated_at" RETURNING "id"`, []any{now, now, nil, "a"}}},
+				si.MySQL: {
+					{"INSERT INTO `counters` (`created_at`,`updated_at`,`deleted_at`,`name`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`),`updated_at`=VALUES(`updated_at`)", []any{now, now, nil, "a"}},
+					{"SELECT `id` FROM `counters` WHERE `name` = ?", []any{"a"}},
+				},
+				si.SQLite: {{`INSERT INTO "counters" ("created_at","updated_at","deleted_at","name") VALUES (?,?,?,?) ON CONFLICT ("name") DO UPDATE SET "name"=EXCLUDED."name","updated_at"=EXCLUDED."updated_at" RETURNING "id"`, []any{now, now, nil, "a"}}},
+			},
+		},
+		{
+			name: "update",
+			run: func(db si.DB) error {
+				id := int64(7)
+				c := counter{Name: "a"}
+				c.ID = &id
+				return si.Update(db, &c, []string{"name"})
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`UPDATE "counters" SET "name"=$1 WHERE "id" = $2`, []any{"a", int64(7)}}},
+				si.MySQL:    {{"UPDATE `counters` SET `name`=? WHERE `id` = ?", []any{"a", int64(7)}}},
+				si.SQLite:   {{`UPDATE "counters" SET "name"=? WHERE "id" = ?`, []any{"a", int64(7)}}},
+			},
+		},
+		{
+			name: "delete",
+			run: func(db si.DB) error {
+				return si.DeleteHard[counter](db, int64(7))
+			},
+			want: map[si.Dialect][]query{
+				si.Postgres: {{`DELETE FROM "counters" WHERE "id" = $1`, []any{int64(7)}}},
+				si.MySQL:    {{"DELETE FROM `counters` WHERE `id` = ?", []any{int64(7)}}},
+				si.SQLite:   {{`DELETE FROM "counters" WHERE "id" = ?`, []any{int64(7)}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for d, want := range tt.want {
+			t.Run(tt.name+"/"+reflect.TypeOf(d).Name(), func(t *testing.T) {
+				db := &fakeDB{rows: tt.rows, dialect: d}
+				err := tt.run(db)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(db.queries) != len(want) {
+					t.Fatalf("expected %d queries, got %q", len(want), db.queries)
+				}
+				for i, q := range want {
+					if db.queries[i] != q.sql {
+						t.Errorf("query %d:\nwant %s\ngot  %s", i, q.sql, db.queries[i])
+					}
+					if got := values(db.args[i]); !reflect.DeepEqual(got, q.args) {
+						t.Errorf("arguments %d:\nwant %#v\ngot  %#v", i, q.args, got)
+					}
+				}
+			})
+		}
+	}
+}
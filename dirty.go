@@ -0,0 +1,110 @@
+package si
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Change is the value of a column when the model was loaded or saved, and the current value.
+type Change struct {
+	Old any
+	New any
+}
+
+// IsDirty will return true if any of `columns`, or any column if none are given, is changed since the model was loaded or saved.
+// A model that is not loaded or saved is always dirty.
+// A value that is changed in place, e.g. an item in a slice, is not detected. Set a new value instead.
+func IsDirty[T Modeler](m *T, columns ...string) bool {
+	changes, ok := changesOf(reflect.ValueOf(m))
+	if !ok {
+		return true
+	}
+	if len(columns) == 0 {
+		return len(changes) > 0
+	}
+	for _, column := range columns {
+		if _, ok := changes[column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes will return the columns that are changed since the model was loaded or saved.
+// It is nil if the model is not loaded or saved.
+func Changes[T Modeler](m *T) map[string]Change {
+	changes, _ := changesOf(reflect.ValueOf(m))
+	return changes
+}
+
+// tracker is implemented by `Model` and `ModelOf`, and so by all models that embed them.
+// The snapshot is a pointer to a shallow copy of the model when it was loaded or saved, which is compared with the model when the changes are needed.
+// It is not changed after it is created, since copies of the model share it.
+type tracker interface {
+	getSnapshot() any
+	setSnapshot(s any)
+}
+
+func (m *Model) getSnapshot() any       { return m.snapshot }
+func (m *Model) setSnapshot(s any)      { m.snapshot = s }
+func (m *ModelOf[K]) getSnapshot() any  { return m.snapshot }
+func (m *ModelOf[K]) setSnapshot(s any) { m.snapshot = s }
+
+// takeSnapshot will remember the values of `columns` on the model that `refVal` points to, or all columns if `columns` is nil.
+// The other columns are kept from the previous snapshot, if there is one.
+func takeSnapshot(refVal reflect.Value, columns []string) {
+	t, ok := refVal.Interface().(tracker)
+	if !ok {
+		return
+	}
+	previous := t.getSnapshot()
+	if columns != nil && previous == nil {
+		return
+	}
+
+	c := reflect.New(refVal.Type().Elem())
+	if columns == nil {
+		c.Elem().Set(refVal.Elem())
+	} else {
+		c.Elem().Set(reflect.ValueOf(previous).Elem())
+		info := getModelInfo(refVal.Type().Elem())
+		for i, column := range info.Columns {
+			if slices.Contains(columns, column) {
+				c.Elem().FieldByIndex(info.indexes[i]).Set(refVal.Elem().FieldByIndex(info.indexes[i]))
+			}
+		}
+	}
+	// The copy must not keep the snapshot of the model, or every snapshot would keep all previous ones.
+	c.Interface().(tracker).setSnapshot(nil)
+	t.setSnapshot(c.Interface())
+}
+
+// changesOf will return the changed columns on the model that `refVal` points to. It is false if there is no snapshot.
+func changesOf(refVal reflect.Value) (map[string]Change, bool) {
+	t, ok := refVal.Interface().(tracker)
+	if !ok || t.getSnapshot() == nil {
+		return nil, false
+	}
+	s := reflect.ValueOf(t.getSnapshot()).Elem()
+	info := getModelInfo(refVal.Type().Elem())
+	changes := map[string]Change{}
+	for i, column := range info.Columns {
+		old := snapshotValue(s.FieldByIndex(info.indexes[i]))
+		value := snapshotValue(refVal.Elem().FieldByIndex(info.indexes[i]))
+		if !reflect.DeepEqual(old, value) {
+			changes[column] = Change{Old: old, New: value}
+		}
+	}
+	return changes, true
+}
+
+// snapshotValue will return the value of a field, without a pointer.
+func snapshotValue(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
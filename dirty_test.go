@@ -0,0 +1,32 @@
+package si_test
+
+import (
+	"testing"
+
+	"github.com/derivatan/si"
+)
+
+func TestChanges(t *testing.T) {
+	db := &fakeDB{rows: 1}
+	a := si.Query[benchArtist]().MustFirst(db)
+	if si.IsDirty(a) || len(si.Changes(a)) != 0 {
+		t.Fatal(si.Changes(a))
+	}
+
+	a.Name = "changed"
+	changes := si.Changes(a)
+	if !si.IsDirty(a, "name") || si.IsDirty(a, "plays") || len(changes) != 1 || changes["name"] != (si.Change{Old: "name", New: "changed"}) {
+		t.Fatal(changes)
+	}
+	err := si.Save(db, a)
+	if err != nil || db.queries[1] != `UPDATE "artists" SET "updated_at"=$1,"name"=$2 WHERE "id" = $3` {
+		t.Fatal(err, db.queries)
+	}
+	if si.IsDirty(a) {
+		t.Fatal(si.Changes(a))
+	}
+	err = si.Save(db, a)
+	if err != nil || len(db.queries) != 2 {
+		t.Fatal(err, db.queries)
+	}
+}
@@ -0,0 +1,122 @@
+package si
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// HasManyThrough is a relationship where there are MULTIPLE other objects (T) that points to this one (F), through an intermediate object (Via).
+// `firstKey` is the field on Via that references F, and `secondKey` is the field on T that references Via.
+// Example:
+// | F    |     | Via      |     | T         |
+// |------|     |----------|     |-----------|
+// |      |     | ID       | <-- | secondKey |
+// | ID   | <-- | firstKey |     | ID        |
+func HasManyThrough[F, Via, T Modeler](model F, firstKey, secondKey string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
+	viaType := reflect.TypeOf(new(Via))
+	toType := reflect.TypeOf(new(T))
+	viaTable := (*new(Via)).GetTable()
+	toTable := (*new(T)).GetTable()
+	firstColumn := getColumnNameString(viaType, firstKey)
+	secondColumn := getColumnNameString(toType, secondKey)
+
+	var get *Q[T]
+	if id, ok := keyValue(model); ok {
+		get = Query[T]().Where(toTable+"."+secondColumn, "IN", Query[Via]().
+			Select([]string{viaTable + "." + keyColumn[Via]()}, nil).
+			Where(viaTable+"."+firstColumn, "=", id),
+		)
+	}
+
+	return &Relation[F, T]{
+		model:        model,
+		query:        Query[T](),
+		get:          get,
+		relationData: relationDataFunc,
+		relationType: hasManyThroughConf[F, Via, T]{
+			firstColumn:  firstColumn,
+			secondColumn: secondColumn,
+			secondValue: func(t T) any {
+				id, _ := fieldValue(reflect.ValueOf(t).FieldByName(secondKey))
+				return id
+			},
+		},
+	}
+}
+
+type hasManyThroughConf[F, Via, T Modeler] struct {
+	firstColumn  string
+	secondColumn string
+	secondValue  func(T) any
+}
+
+func (h hasManyThroughConf[F, Via, T]) collectID(f F) ([]any, bool) {
+	return keyValues(f)
+}
+
+func (h hasManyThroughConf[F, Via, T]) groupBy(t T) []any {
+	return []any{h.secondValue(t)}
+}
+
+func (h hasManyThroughConf[F, Via, T]) queryColumns() []string {
+	return []string{h.secondColumn}
+}
+
+func (h hasManyThroughConf[F, Via, T]) joinColumns() ([]string, []string) {
+	return []string{keyColumn[F]()}, []string{h.firstColumn}
+}
+
+// load will get the related objects joined with the intermediate table, grouped and partitioned by the key of F on the intermediate objects.
+// The intermediate table is joined as a sub query with only the two keys, with names that can not be the same as the columns of T.
+// The intermediate objects are ignored if they are deleted, as when they are queried.
+func (h hasManyThroughConf[F, Via, T]) load(ctx context.Context, db DB, query *Q[T], ids []any) (map[any][]T, error) {
+	c := clientOf(db)
+	d := c.dialect
+	toTable := (*new(T)).GetTable()
+	via := fmt.Sprintf("SELECT %s AS si_via, %s AS si_from FROM %s", d.Quote(keyColumn[Via]()), d.Quote(h.firstColumn), d.Quote((*new(Via)).GetTable()))
+	if c.useDeletedAt {
+		via += " WHERE deleted_at IS NULL"
+	}
+	join := &JoinConf{
+		JoinType: INNER,
+		Table:    "(" + via + ")",
+		Alias:    "si_via",
+		Condition: []filter{
+			{Column: "si_via.si_via", Operation: "=", Value: Raw(toTable + "." + h.secondColumn), Separator: "AND"},
+		},
+		withoutDeletedAt: true,
+	}
+	query.joins = append(query.joins, func(T) *JoinConf { return join })
+	query.filters = append(query.filters, filter{Column: "si_via.si_from", Operation: "IN", Value: ids, Separator: "AND"})
+	return loadGroups(ctx, db, query, "si_via.si_from", keyType[F]())
+}
+
+func (h hasManyThroughConf[F, Via, T]) join(joinType JoinType, toTable string) *JoinConf {
+	fromTable := (*new(F)).GetTable()
+	viaTable := (*new(Via)).GetTable()
+	return &JoinConf{
+		JoinType: joinType,
+		Table:    (*new(T)).GetTable(),
+		Condition: []filter{
+			{
+				Column:    fmt.Sprintf("%s.%s", viaTable, keyColumn[Via]()),
+				Operation: "=",
+				Value:     Raw(fmt.Sprintf("%s.%s", toTable, h.secondColumn)),
+				Separator: "AND",
+			},
+		},
+		through: &JoinConf{
+			JoinType: joinType,
+			Table:    viaTable,
+			Condition: []filter{
+				{
+					Column:    fmt.Sprintf("%s.%s", fromTable, keyColumn[F]()),
+					Operation: "=",
+					Value:     Raw(fmt.Sprintf("%s.%s", viaTable, h.firstColumn)),
+					Separator: "AND",
+				},
+			},
+		},
+	}
+}
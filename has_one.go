@@ -2,11 +2,10 @@ package si
 
 import (
 	"reflect"
-
-	"github.com/google/uuid"
 )
 
 // HasOne is a relationship where there are ONE other objects (T) that points to this one (F).
+// For a composite key, the reference is a comma separated list of fields, in the same order as the primary key of F.
 // Example:
 // | F    |     | T    |
 // |------|     |------|
@@ -16,44 +15,50 @@ func HasOne[F, T Modeler](model F, refFieldName, fieldName string, relationDataF
 	fromType := reflect.TypeOf(new(F))
 	toType := reflect.TypeOf(new(T))
 	relationFieldName := getRelationFieldName(fromType, toType, fieldName, false)
-	column := getColumnNameString(toType, relationFieldName)
-	refColumn := getColumnNameString(toType, refFieldName)
+	relationFieldNames := splitFieldNames(relationFieldName)
+	columns := columnsFor(toType, relationFieldName)
+	refColumns := columnsFor(toType, refFieldName)
+
+	var get *Q[T]
+	if id, ok := keyValues(model); ok {
+		get = Query[T]()
+		get.filters = keyFilters(columns, id)
+	}
 
 	return &Relation[F, T]{
 		model:        model,
 		query:        Query[T](),
-		get:          Query[T]().Where(column, "=", model.GetModel().ID),
+		get:          get,
 		relationData: relationDataFunc,
 		relationType: hasOneConf[F, T]{
-			idColumn:  column,
-			refColumn: refColumn,
-			idValue: func(t T) uuid.UUID {
-				tVal := reflect.ValueOf(t)
-				field := tVal.FieldByName(relationFieldName)
-				return field.Interface().(uuid.UUID)
+			idColumns:  columns,
+			refColumns: refColumns,
+			idValue: func(t T) []any {
+				id, _ := fieldValues(t, relationFieldNames)
+				return id
 			},
 		},
 	}
 }
 
 type hasOneConf[F, T Modeler] struct {
-	idColumn  string
-	refColumn string
-	idValue   func(T) uuid.UUID
+	idColumns  []string
+	refColumns []string
+	idValue    func(T) []any
 }
 
-func (h hasOneConf[F, T]) collectID(f F) uuid.UUID {
-	return *f.GetModel().ID
+func (h hasOneConf[F, T]) collectID(f F) ([]any, bool) {
+	return keyValues(f)
 }
 
-func (h hasOneConf[F, T]) groupBy(t T) uuid.UUID {
+func (h hasOneConf[F, T]) groupBy(t T) []any {
 	return h.idValue(t)
 }
 
-func (h hasOneConf[F, T]) queryColumn() string {
-	return h.idColumn
+func (h hasOneConf[F, T]) queryColumns() []string {
+	return h.idColumns
 }
 
-func (h hasOneConf[F, T]) joinColumns() (string, string) {
-	return "id", h.refColumn
+func (h hasOneConf[F, T]) joinColumns() ([]string, []string) {
+	return keyColumns[F](), h.refColumns
 }
@@ -0,0 +1,129 @@
+package si
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// The hooks can be implemented by a model to be called by si, with a pointer receiver if the model is changed.
+// If a hook before an operation returns an error, the operation is aborted and the error is returned.
+// If a hook after an operation returns an error, it is returned, but the operation is already done. Use a `Transaction` to roll it back.
+// Upsert calls BeforeSave and AfterSave, since it is not known if the model is inserted or updated.
+
+// BeforeSaver is called before a model is inserted or updated, before BeforeCreate and BeforeUpdate.
+type BeforeSaver interface {
+	BeforeSave(ctx context.Context, db DB) error
+}
+
+// AfterSaver is called after a model is inserted or updated, after AfterCreate and AfterUpdate.
+type AfterSaver interface {
+	AfterSave(ctx context.Context, db DB) error
+}
+
+// BeforeCreater is called before a model is inserted.
+type BeforeCreater interface {
+	BeforeCreate(ctx context.Context, db DB) error
+}
+
+// AfterCreater is called after a model is inserted, when the id is set.
+type AfterCreater interface {
+	AfterCreate(ctx context.Context, db DB) error
+}
+
+// BeforeUpdater is called before a model is updated.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, db DB) error
+}
+
+// AfterUpdater is called after a model is updated.
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context, db DB) error
+}
+
+// BeforeDeleter is called before a model is deleted, on a model with only the primary key set.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context, db DB) error
+}
+
+// AfterDeleter is called after a model is deleted, on a model with only the primary key set.
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context, db DB) error
+}
+
+// AfterFinder is called after a model is queried, when the relations from `With` are loaded.
+type AfterFinder interface {
+	AfterFind(ctx context.Context, db DB) error
+}
+
+// hook will call a hook on a model, if it implements it.
+type hook func(ctx context.Context, db DB, m any) error
+
+func newHook[H any](name string, call func(h H, ctx context.Context, db DB) error) hook {
+	return func(ctx context.Context, db DB, m any) error {
+		h, ok := m.(H)
+		if !ok {
+			return nil
+		}
+		err := call(h, ctx, db)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+}
+
+var (
+	beforeSave   = newHook("beforeSave", BeforeSaver.BeforeSave)
+	afterSave    = newHook("afterSave", AfterSaver.AfterSave)
+	beforeCreate = newHook("beforeCreate", BeforeCreater.BeforeCreate)
+	afterCreate  = newHook("afterCreate", AfterCreater.AfterCreate)
+	beforeUpdate = newHook("beforeUpdate", BeforeUpdater.BeforeUpdate)
+	afterUpdate  = newHook("afterUpdate", AfterUpdater.AfterUpdate)
+	beforeDelete = newHook("beforeDelete", BeforeDeleter.BeforeDelete)
+	afterDelete  = newHook("afterDelete", AfterDeleter.AfterDelete)
+	afterFind    = newHook("afterFind", AfterFinder.AfterFind)
+)
+
+// runHooks will call the hooks in order, on all models.
+func runHooks[T Modeler](ctx context.Context, db DB, models []*T, hooks ...hook) error {
+	for _, h := range hooks {
+		for _, m := range models {
+			err := h(ctx, db, m)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteHooks will return a model with only the primary key set to `id`, if T has any delete hooks.
+func deleteHooks[T Modeler](id []any) ([]*T, error) {
+	switch any(new(T)).(type) {
+	case BeforeDeleter, AfterDeleter:
+		m, err := keyModel[T](id)
+		if err != nil {
+			return nil, err
+		}
+		return []*T{m}, nil
+	}
+	return nil, nil
+}
+
+// keyModel will return a model with only the primary key set to `id`, for the delete hooks.
+func keyModel[T Modeler](id []any) (*T, error) {
+	m := new(T)
+	refVal := reflect.ValueOf(m).Elem()
+	info := getModelInfo(refVal.Type())
+	if len(id) != len(info.key) {
+		return nil, fmt.Errorf("expected %d values for the primary key of '%s', got %d", len(info.key), (*m).GetTable(), len(id))
+	}
+	for i, index := range info.key {
+		err := setID(refVal.FieldByIndex(info.indexes[index]), id[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,121 @@
+package si
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator will return a new id for a model that is inserted without one.
+// The value must be assignable or convertible to the type of the id, e.g. a `uuid.UUID` for `si.Model`.
+type IDGenerator func() any
+
+// NewIDer can be implemented by a model to generate its own ids, instead of the generator on the client.
+// It can have a pointer receiver, like the hooks.
+type NewIDer interface {
+	NewID() any
+}
+
+// UUIDv4 will return a random `uuid.UUID`.
+func UUIDv4() any {
+	return uuid.New()
+}
+
+// UUIDv7 will return a time-ordered `uuid.UUID`.
+func UUIDv7() any {
+	return uuid.Must(uuid.NewV7())
+}
+
+// ULID will return a ULID, which is a timestamp in milliseconds in the first 48 bits and 80 random bits.
+// It is set as a `uuid.UUID` with the same bytes, or as the 26 characters text of the ULID on a string field.
+func ULID() any {
+	var id ulid
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], ms[2:])
+	_, err := rand.Read(id[6:])
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// ulid is the 16 bytes of a ULID. It can be converted to a `uuid.UUID`.
+type ulid [16]byte
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// String will return the ULID as 26 characters in Crockford's base32.
+func (id ulid) String() string {
+	var text [26]byte
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	for i := len(text) - 1; i >= 0; i-- {
+		text[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(text[:])
+}
+
+// generateIDs will set a new id on the models that does not have one, if there is a generator for the model or on the client.
+// Only keys with one column are generated. The returned fields should be reset if the insert fails.
+func generateIDs[T Modeler](c *Client, tis []typeInfo) ([]reflect.Value, error) {
+	generate := c.idGenerator
+	if g, ok := any(new(T)).(NewIDer); ok {
+		generate = g.NewID
+	}
+	if generate == nil {
+		return nil, nil
+	}
+
+	var generated []reflect.Value
+	for _, ti := range tis {
+		if len(ti.Key) != 1 || len(unsetKeys(ti)) == 0 {
+			continue
+		}
+		field := reflect.ValueOf(ti.Values[ti.Key[0]]).Elem()
+		err := setID(field, generate())
+		if err != nil {
+			resetIDs(generated)
+			return nil, err
+		}
+		generated = append(generated, field)
+	}
+	return generated, nil
+}
+
+// setID will set `id` on the field, which can be a pointer.
+// An id that is a `fmt.Stringer` is set as its string on a string field, e.g. a `uuid.UUID` for a text column.
+func setID(field reflect.Value, id any) error {
+	t := field.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := id.(fmt.Stringer); ok && t.Kind() == reflect.String {
+		id = s.String()
+	}
+	value := reflect.ValueOf(id)
+	if !value.IsValid() || !value.Type().ConvertibleTo(t) || (t.Kind() == reflect.String && value.Kind() != reflect.String) {
+		return fmt.Errorf("the id '%v' can not be set as '%s'", id, t)
+	}
+	value = value.Convert(t)
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	field.Set(value)
+	return nil
+}
+
+// resetIDs will remove the generated ids, so the models are inserted again when they are saved.
+func resetIDs(fields []reflect.Value) {
+	for _, field := range fields {
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
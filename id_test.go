@@ -0,0 +1,25 @@
+package si_test
+
+import (
+	"testing"
+
+	"github.com/derivatan/si"
+)
+
+type slug struct {
+	si.ModelOf[string]
+	Name string
+}
+
+func (s slug) GetTable() string { return "slugs" }
+
+func (s *slug) NewID() any { return "generated" }
+
+func TestNewIDPointerReceiver(t *testing.T) {
+	db := &fakeDB{}
+	s := slug{Name: "a"}
+	err := si.Save(db, &s)
+	if err != nil || s.ID == nil || *s.ID != "generated" {
+		t.Fatal(err, s.ID, db.queries)
+	}
+}
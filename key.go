@@ -0,0 +1,227 @@
+package si
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ModelOf is same as Model, but with another type of id, e.g. `si.ModelOf[int64]` for a `bigserial` column.
+type ModelOf[K comparable] struct {
+	ID        *K         `si:"id" json:"id"`
+	CreatedAt time.Time  `si:"created_at" json:"createdAt"`
+	UpdatedAt *time.Time `si:"updated_at" json:"updatedAt"`
+	DeletedAt *time.Time `si:"deleted_at" json:"deletedAt"`
+
+	// snapshot is a pointer to a copy of the model when it was loaded or saved, for `Changes`.
+	snapshot any
+}
+
+// GetModel will return the timestamps of the model. The ID is not set, since it is not a `uuid.UUID`.
+func (m ModelOf[K]) GetModel() Model {
+	return Model{
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		DeletedAt: m.DeletedAt,
+	}
+}
+
+// PrimaryKeyer can be implemented by a model to use other columns than `id` as the primary key, e.g. a composite key.
+type PrimaryKeyer interface {
+	PrimaryKey() []string
+}
+
+// keyColumns will return the columns of the primary key of M.
+func keyColumns[M Modeler]() []string {
+	info := getModelInfo(reflect.TypeOf((*M)(nil)).Elem())
+	var result []string
+	for _, index := range info.key {
+		result = append(result, info.Columns[index])
+	}
+	return result
+}
+
+// keyColumn will return the column of the primary key of M, which must not be composite.
+// This is used by the relations that only support a single column.
+func keyColumn[M Modeler]() string {
+	columns := keyColumns[M]()
+	if len(columns) != 1 {
+		panic(fmt.Sprintf("model '%s' must have a primary key with one column", reflect.TypeOf((*M)(nil)).Elem().Name()))
+	}
+	return columns[0]
+}
+
+// keyType will return the type of the first column of the primary key of M, without a pointer.
+func keyType[M Modeler]() reflect.Type {
+	refType := reflect.TypeOf((*M)(nil)).Elem()
+	info := getModelInfo(refType)
+	if len(info.key) == 0 {
+		panic(fmt.Sprintf("model '%s' does not have a primary key", refType.Name()))
+	}
+	t := refType.FieldByIndex(info.indexes[info.key[0]]).Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// keyValues will return the values of the primary key of `m`. It is false if any of them is not set.
+func keyValues(m Modeler) ([]any, bool) {
+	refVal := reflect.ValueOf(m)
+	info := getModelInfo(refVal.Type())
+	if len(info.key) == 0 {
+		return nil, false
+	}
+	var result []any
+	for _, index := range info.key {
+		value, ok := keyPart(refVal.FieldByIndex(info.indexes[index]), len(info.key))
+		if !ok {
+			return nil, false
+		}
+		result = append(result, value)
+	}
+	return result, true
+}
+
+// keyValue will return the value of the primary key of `m`, if it is one column. It is false if it is not set.
+func keyValue(m Modeler) (any, bool) {
+	values, ok := keyValues(m)
+	if !ok || len(values) != 1 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// fieldValues will return the values of the fields `names` on `m`. It is false if any of them is not set.
+func fieldValues(m any, names []string) ([]any, bool) {
+	refVal := reflect.ValueOf(m)
+	var result []any
+	for _, name := range names {
+		value, ok := keyPart(refVal.FieldByName(name), len(names))
+		if !ok {
+			return nil, false
+		}
+		result = append(result, value)
+	}
+	return result, true
+}
+
+// fieldValue will return the value of a field, without a pointer. It is false if it is nil or the zero value.
+func fieldValue(v reflect.Value) (any, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.IsZero() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// keyPart will return the value of a field in a key with `n` columns, without a pointer.
+// It is false if it is nil, or the zero value in a key with one column, since such a key is set on insert.
+// In a composite key, only nil means not set, since the zero value, e.g. 0 or false, is a valid part of it.
+func keyPart(v reflect.Value, n int) (any, bool) {
+	if n == 1 {
+		return fieldValue(v)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), true
+}
+
+// keyOf will return `id` as `t`, the type of a key, so it can be compared with keys that are scanned from the database.
+// A pointer is dereferenced, and a string is parsed if `t` implements `encoding.TextUnmarshaler`, e.g. a `uuid.UUID`.
+func keyOf(id any, t reflect.Type) (any, error) {
+	v := reflect.ValueOf(id)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+		id = v.Interface()
+	}
+	if v.Kind() == reflect.String && t.Kind() != reflect.String {
+		if u, ok := reflect.New(t).Interface().(encoding.TextUnmarshaler); ok {
+			err := u.UnmarshalText([]byte(v.String()))
+			if err != nil {
+				return nil, fmt.Errorf("the id '%v' can not be used as '%s': %w", id, t, err)
+			}
+			return reflect.ValueOf(u).Elem().Interface(), nil
+		}
+	}
+	key := reflect.New(t).Elem()
+	err := setID(key, id)
+	if err != nil {
+		return nil, err
+	}
+	return key.Interface(), nil
+}
+
+// compositeKey is the key in maps for the values of more than one column.
+type compositeKey string
+
+// newKey will return a value for `values` that can be used as a key in maps.
+func newKey(values []any) any {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return compositeKey(fmt.Sprintf("%#v", values))
+}
+
+// keyFilters will return the filters for `columns` being equal to `values`.
+func keyFilters(columns []string, values []any) []filter {
+	var result []filter
+	for i, column := range columns {
+		result = append(result, filter{Column: column, Operation: "=", Value: values[i], Separator: "AND"})
+	}
+	return result
+}
+
+// keysFilter will return a filter for `columns` being equal to any of `keys`.
+func keysFilter(columns []string, keys [][]any) filter {
+	if len(columns) == 1 {
+		var values []any
+		for _, key := range keys {
+			values = append(values, key[0])
+		}
+		return filter{Column: columns[0], Operation: "IN", Value: values, Separator: "AND"}
+	}
+	var sub []filter
+	for _, key := range keys {
+		sub = append(sub, filter{Sub: keyFilters(columns, key), Separator: "OR"})
+	}
+	return filter{Sub: sub, Separator: "AND"}
+}
+
+// columnsFor will return the columns for a comma separated list of field names on `t`.
+func columnsFor(t reflect.Type, fieldNames string) []string {
+	var result []string
+	for _, name := range splitFieldNames(fieldNames) {
+		result = append(result, getColumnNameString(t, name))
+	}
+	return result
+}
+
+// splitFieldNames will split a comma separated list of field names, which is used for composite keys.
+func splitFieldNames(fieldNames string) []string {
+	result := strings.Split(fieldNames, ",")
+	for i := range result {
+		result[i] = strings.TrimSpace(result[i])
+	}
+	return result
+}
+
+// qualify will prefix all `columns` with the table.
+func qualify(table string, columns []string) []string {
+	var result []string
+	for _, column := range columns {
+		result = append(result, table+"."+column)
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package si
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pathLoader is implemented by all relations, so they can be loaded by name with `Load`.
+type pathLoader interface {
+	loadPaths(ctx context.Context, db DB, result any, paths [][]string) error
+}
+
+// loadPaths will load the relations in `paths` on all `models`.
+// The first name in each path is the method on M that returns the relation.
+func loadPaths[M Modeler](ctx context.Context, db DB, models []M, paths [][]string) error {
+	var names []string
+	nested := map[string][][]string{}
+	for _, path := range paths {
+		if _, ok := nested[path[0]]; !ok {
+			names = append(names, path[0])
+			nested[path[0]] = nil
+		}
+		if len(path) > 1 {
+			nested[path[0]] = append(nested[path[0]], path[1:])
+		}
+	}
+
+	for _, name := range names {
+		loader, err := relationByName(models[0], name)
+		if err != nil {
+			return fmt.Errorf("si.load: %w", err)
+		}
+		err = loader.loadPaths(ctx, db, models, nested[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relationByName will call the method `name` on `m`, which must return a relation.
+func relationByName[M Modeler](m M, name string) (pathLoader, error) {
+	method := reflect.ValueOf(m).MethodByName(name)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, fmt.Errorf("'%s' is not a relation on '%s'", name, reflect.TypeOf(m).Name())
+	}
+	out := method.Call(nil)[0]
+	if out.Kind() != reflect.Pointer && out.CanInterface() {
+		// The relation is returned by value, so a pointer is needed for the methods.
+		ptr := reflect.New(out.Type())
+		ptr.Elem().Set(out)
+		out = ptr
+	}
+	loader, ok := out.Interface().(pathLoader)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a relation on '%s'", name, reflect.TypeOf(m).Name())
+	}
+	return loader, nil
+}
+
+// loadPaths will execute the relation on `result`, and then load the nested `paths` on all the related objects.
+func (r *Relation[F, T]) loadPaths(ctx context.Context, db DB, result any, paths [][]string) error {
+	models := result.([]F)
+	err := r.ExecuteContext(ctx, db, models)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// Load the nested relations on all related objects at once, and then put them back where they came from.
+	var related []T
+	for i := range models {
+		related = append(related, r.relationData(&models[i]).data...)
+	}
+	if len(related) == 0 {
+		return nil
+	}
+	err = loadPaths(ctx, db, related, paths)
+	if err != nil {
+		return err
+	}
+	offset := 0
+	for i := range models {
+		rd := r.relationData(&models[i])
+		rd.data = related[offset : offset+len(rd.data) : offset+len(rd.data)]
+		offset += len(rd.data)
+	}
+	return nil
+}
+
+func splitPaths(paths []string) [][]string {
+	var result [][]string
+	for _, path := range paths {
+		result = append(result, strings.Split(path, "."))
+	}
+	return result
+}
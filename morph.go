@@ -0,0 +1,252 @@
+package si
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// MorphTyper can be implemented by a model to set the value that is stored in the type column of polymorphic relations.
+// If it is not implemented, the table name is used.
+type MorphTyper interface {
+	MorphType() string
+}
+
+func morphTypeOf[M Modeler]() string {
+	var m M
+	if mt, ok := any(m).(MorphTyper); ok {
+		return mt.MorphType()
+	}
+	return m.GetTable()
+}
+
+// MorphMany is a relationship where there are MULTIPLE other objects (T) that points to this one (F), together with its type.
+// The columns on T are `name_type` and `name_id`, and the type is the table of F, unless F implements `MorphTyper`.
+// Example:
+// | F    |     | T         |
+// |------|     |-----------|
+// |      |     | ID        |
+// |      |     | name_type | = F
+// | ID   | <-- | name_id   |
+func MorphMany[F, T Modeler](model F, name string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
+	typeColumn := name + "_type"
+	idColumn := name + "_id"
+	morphType := morphTypeOf[F]()
+	idIndex := fieldIndexByColumn[T](idColumn)
+	fromKey := keyType[F]()
+
+	var get *Q[T]
+	if id, ok := keyValue(model); ok {
+		get = Query[T]().Where(idColumn, "=", id).Where(typeColumn, "=", morphType)
+	}
+
+	return &Relation[F, T]{
+		model:        model,
+		query:        Query[T](),
+		get:          get,
+		relationData: relationDataFunc,
+		relationType: morphManyConf[F, T]{
+			typeColumn: typeColumn,
+			idColumn:   idColumn,
+			morphType:  morphType,
+			idValue: func(t T) any {
+				id, _ := morphID(reflect.ValueOf(t).FieldByIndex(idIndex), fromKey)
+				return id
+			},
+		},
+	}
+}
+
+// MorphOne is a relationship where there are ONE other object (T) that points to this one (F), together with its type.
+// It is defined in the same way as MorphMany.
+func MorphOne[F, T Modeler](model F, name string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
+	return MorphMany[F, T](model, name, relationDataFunc)
+}
+
+type morphManyConf[F, T Modeler] struct {
+	typeColumn string
+	idColumn   string
+	morphType  string
+	idValue    func(T) any
+}
+
+func (m morphManyConf[F, T]) collectID(f F) ([]any, bool) {
+	return keyValues(f)
+}
+
+func (m morphManyConf[F, T]) groupBy(t T) []any {
+	return []any{m.idValue(t)}
+}
+
+func (m morphManyConf[F, T]) queryColumns() []string {
+	return []string{m.idColumn}
+}
+
+func (m morphManyConf[F, T]) joinColumns() ([]string, []string) {
+	return []string{keyColumn[F]()}, []string{m.idColumn}
+}
+
+func (m morphManyConf[F, T]) load(ctx context.Context, db DB, query *Q[T], ids []any) (map[any][]T, error) {
+	query.partition = m.idColumn
+	related, err := query.Where(m.typeColumn, "=", m.morphType).Where(m.idColumn, "IN", ids).GetContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	result := map[any][]T{}
+	for _, r := range related {
+		id := m.idValue(r)
+		result[id] = append(result[id], r)
+	}
+	return result, nil
+}
+
+func (m morphManyConf[F, T]) join(joinType JoinType, toTable string) *JoinConf {
+	fromTable := (*new(F)).GetTable()
+	return &JoinConf{
+		JoinType: joinType,
+		Table:    (*new(T)).GetTable(),
+		Condition: []filter{
+			{
+				Column:    fmt.Sprintf("%s.%s", fromTable, keyColumn[F]()),
+				Operation: "=",
+				Value:     Raw(fmt.Sprintf("%s.%s", toTable, m.idColumn)),
+				Separator: "AND",
+			},
+			{
+				Column:    fmt.Sprintf("%s.%s", toTable, m.typeColumn),
+				Operation: "=",
+				Value:     m.morphType,
+				Separator: "AND",
+			},
+		},
+	}
+}
+
+// MorphTo is the inverse of MorphMany and MorphOne, where this object (F) has a reference to another object together with its type.
+// Since the other object can be of different types, there must be one relation for each type (T), which is empty if the type does not match.
+// Example:
+// | F         |     | T  |
+// |-----------|     |----|
+// | ID        |     |    |
+// | name_type |     |    | = T
+// | name_id   | --> | ID |
+func MorphTo[F, T Modeler](model F, name string, relationDataFunc func(f *F) *RelationData[T]) *Relation[F, T] {
+	typeColumn := name + "_type"
+	idColumn := name + "_id"
+	morphType := morphTypeOf[T]()
+	typeIndex := fieldIndexByColumn[F](typeColumn)
+	idIndex := fieldIndexByColumn[F](idColumn)
+	toKey := keyType[T]()
+	idField := func(f F) ([]any, bool) {
+		val := reflect.ValueOf(f)
+		if t, ok := stringValue(val.FieldByIndex(typeIndex)); !ok || t != morphType {
+			return nil, false
+		}
+		id, ok := morphID(val.FieldByIndex(idIndex), toKey)
+		return []any{id}, ok
+	}
+
+	var get *Q[T]
+	if id, ok := idField(model); ok {
+		get = Query[T]().Where(keyColumn[T](), "=", id[0])
+	}
+
+	return &Relation[F, T]{
+		model:        model,
+		query:        Query[T](),
+		get:          get,
+		relationData: relationDataFunc,
+		relationType: morphToConf[F, T]{
+			typeColumn: typeColumn,
+			idColumn:   idColumn,
+			morphType:  morphType,
+			idField:    idField,
+		},
+	}
+}
+
+type morphToConf[F, T Modeler] struct {
+	typeColumn string
+	idColumn   string
+	morphType  string
+	idField    func(F) ([]any, bool)
+}
+
+func (m morphToConf[F, T]) collectID(f F) ([]any, bool) {
+	return m.idField(f)
+}
+
+func (m morphToConf[F, T]) groupBy(t T) []any {
+	id, _ := keyValues(t)
+	return id
+}
+
+func (m morphToConf[F, T]) queryColumns() []string {
+	return []string{keyColumn[T]()}
+}
+
+func (m morphToConf[F, T]) joinColumns() ([]string, []string) {
+	return []string{m.idColumn}, []string{keyColumn[T]()}
+}
+
+func (m morphToConf[F, T]) join(joinType JoinType, toTable string) *JoinConf {
+	fromTable := (*new(F)).GetTable()
+	return &JoinConf{
+		JoinType: joinType,
+		Table:    (*new(T)).GetTable(),
+		Condition: []filter{
+			{
+				Column:    fmt.Sprintf("%s.%s", fromTable, m.idColumn),
+				Operation: "=",
+				Value:     Raw(fmt.Sprintf("%s.%s", toTable, keyColumn[T]())),
+				Separator: "AND",
+			},
+			{
+				Column:    fmt.Sprintf("%s.%s", fromTable, m.typeColumn),
+				Operation: "=",
+				Value:     m.morphType,
+				Separator: "AND",
+			},
+		},
+	}
+}
+
+// morphID will return the value of the id column as `t`, the type of the key it references, so it can be compared with the keys.
+// The id column is often a string, for all the types of keys. It is false if it is not set, or can not be used as `t`.
+func morphID(v reflect.Value, t reflect.Type) (any, bool) {
+	id, ok := fieldValue(v)
+	if !ok {
+		return nil, false
+	}
+	key, err := keyOf(id, t)
+	if err != nil {
+		return nil, false
+	}
+	return key, true
+}
+
+// stringValue will return the value of a string field, which can be a pointer or a named string type. It is false if it is nil.
+func stringValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// fieldIndexByColumn will return the index of the field with the column name `column` on M.
+func fieldIndexByColumn[M Modeler](column string) []int {
+	refType := reflect.TypeOf((*M)(nil)).Elem()
+	info := getModelInfo(refType)
+	index := slices.Index(info.Columns, column)
+	if index < 0 {
+		panic(fmt.Sprintf("'%s' is not a column on model '%s'", column, refType.Name()))
+	}
+	return info.indexes[index]
+}
@@ -0,0 +1,260 @@
+package si
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// CursorPage is one page of a result from `Paginate`.
+// `Next` and `Previous` are opaque cursors to use for the next call to `Paginate`, and are empty if there is no such page.
+type CursorPage[T Modeler] struct {
+	Items    []T
+	Next     string
+	Previous string
+}
+
+// PageResult is one page of a result from `Page`.
+type PageResult[T Modeler] struct {
+	Items    []T
+	Page     int
+	PerPage  int
+	Total    int64
+	LastPage int
+	HasNext  bool
+}
+
+type cursor struct {
+	Backward bool              `json:"b,omitempty"`
+	Values   []json.RawMessage `json:"v"`
+}
+
+// Paginate will return one page of the query, using the `OrderBy` columns and the id to find where the page starts.
+// This is faster and more stable than `Take` and `Skip` on big tables, but the pages can not be numbered.
+// `cursor` should be empty for the first page, and one of the cursors in the result for the following pages.
+// The `OrderBy` columns must be columns on the model, and should not be nullable.
+func (q *Q[T]) Paginate(db DB, perPage int, cursor string) (*CursorPage[T], error) {
+	return q.PaginateContext(context.Background(), db, perPage, cursor)
+}
+
+// PaginateContext is same as Paginate, but with a context.
+func (q *Q[T]) PaginateContext(ctx context.Context, db DB, perPage int, cursor string) (*CursorPage[T], error) {
+	if perPage < 1 {
+		return nil, fmt.Errorf("si.paginate: invalid page size %d", perPage)
+	}
+	orders := q.cursorOrder()
+	fields, err := cursorFields[T](orders)
+	if err != nil {
+		return nil, fmt.Errorf("si.paginate: %w", err)
+	}
+
+	pq := *q
+	pq.filters = slices.Clone(q.filters)
+	pq.orderBy = orders
+	pq.take = perPage + 1
+	pq.skip = 0
+
+	c, err := decodeCursor[T](cursor, fields)
+	if err != nil {
+		return nil, fmt.Errorf("si.paginate: %w", err)
+	}
+	if c != nil {
+		if c.backward {
+			for i := range pq.orderBy {
+				pq.orderBy[i].Ascending = !pq.orderBy[i].Ascending
+			}
+		}
+		pq.filters = append(pq.filters, filter{Separator: "AND", Sub: seekFilters(pq.orderBy, c.values)})
+	}
+
+	items, err := pq.GetContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("si.paginate: %w", err)
+	}
+	more := len(items) > perPage
+	if more {
+		items = items[:perPage]
+	}
+	backward := c != nil && c.backward
+	if backward {
+		slices.Reverse(items)
+	}
+
+	result := &CursorPage[T]{Items: items}
+	if len(items) == 0 {
+		return result, nil
+	}
+	if more || backward {
+		result.Next, err = encodeCursor(items[len(items)-1], fields, false)
+		if err != nil {
+			return nil, fmt.Errorf("si.paginate: %w", err)
+		}
+	}
+	if (more && backward) || (c != nil && !backward) {
+		result.Previous, err = encodeCursor(items[0], fields, true)
+		if err != nil {
+			return nil, fmt.Errorf("si.paginate: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Page will return the numbered `page`, starting at 1, with `perPage` items per page.
+// The total number of rows is counted with the same filters and joins, which requires an extra query.
+func (q *Q[T]) Page(db DB, page, perPage int) (*PageResult[T], error) {
+	return q.PageContext(context.Background(), db, page, perPage)
+}
+
+// PageContext is same as Page, but with a context.
+func (q *Q[T]) PageContext(ctx context.Context, db DB, page, perPage int) (*PageResult[T], error) {
+	if page < 1 || perPage < 1 {
+		return nil, fmt.Errorf("si.page: invalid page %d with size %d", page, perPage)
+	}
+	total, err := q.CountContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("si.page: %w", err)
+	}
+
+	q.take = perPage
+	q.skip = (page - 1) * perPage
+	items, err := q.GetContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("si.page: %w", err)
+	}
+
+	lastPage := int((total + int64(perPage) - 1) / int64(perPage))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	return &PageResult[T]{
+		Items:    items,
+		Page:     page,
+		PerPage:  perPage,
+		Total:    total,
+		LastPage: lastPage,
+		HasNext:  page < lastPage,
+	}, nil
+}
+
+// cursorOrder is the order of the query, with the primary key added last to make it unique.
+func (q *Q[T]) cursorOrder() []orderBy {
+	table := (*new(T)).GetTable()
+	orders := slices.Clone(q.orderBy)
+	for _, column := range keyColumns[T]() {
+		if !slices.ContainsFunc(orders, func(o orderBy) bool {
+			return o.Column == column || o.Column == table+"."+column
+		}) {
+			orders = append(orders, orderBy{Column: table + "." + column, Ascending: true})
+		}
+	}
+	return orders
+}
+
+// cursorFields will find the index in the type info, for each of the order columns.
+func cursorFields[T Modeler](orders []orderBy) ([]int, error) {
+	table := (*new(T)).GetTable()
+	columns := getTypeInfo(new(T)).Columns
+	var result []int
+	for _, o := range orders {
+		index := slices.Index(columns, strings.TrimPrefix(o.Column, table+"."))
+		if index < 0 {
+			return nil, fmt.Errorf("can not paginate on '%s', it is not a column on '%s'", o.Column, table)
+		}
+		result = append(result, index)
+	}
+	return result, nil
+}
+
+// seekFilters will create the condition for everything after the values, for the given order.
+// For the columns a, b and c, it is: a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+func seekFilters(orders []orderBy, values []any) []filter {
+	var result []filter
+	for i, o := range orders {
+		var sub []filter
+		for j := 0; j < i; j++ {
+			sub = append(sub, filter{Column: orders[j].Column, Operation: "=", Value: values[j], Separator: "AND"})
+		}
+		op := ">"
+		if !o.Ascending {
+			op = "<"
+		}
+		sub = append(sub, filter{Column: o.Column, Operation: op, Value: values[i], Separator: "AND"})
+		result = append(result, filter{Separator: "OR", Sub: sub})
+	}
+	return result
+}
+
+// seekValues will return the values of the order columns on `m`, for `seekFilters`.
+func seekValues[T Modeler](m T, fields []int) []any {
+	ti := getTypeInfo(&m)
+	var result []any
+	for _, index := range fields {
+		value := reflect.ValueOf(ti.Values[index]).Elem()
+		if value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		result = append(result, value.Interface())
+	}
+	return result
+}
+
+func encodeCursor[T Modeler](m T, fields []int, backward bool) (string, error) {
+	ti := getTypeInfo(&m)
+	c := cursor{Backward: backward}
+	for _, index := range fields {
+		value, err := json.Marshal(reflect.ValueOf(ti.Values[index]).Elem().Interface())
+		if err != nil {
+			return "", fmt.Errorf("encode cursor: %w", err)
+		}
+		c.Values = append(c.Values, value)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+type decodedCursor struct {
+	backward bool
+	values   []any
+}
+
+// decodeCursor will decode the cursor, with each value as the same type as the field on the model.
+func decodeCursor[T Modeler](s string, fields []int) (*decodedCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor: %w", err)
+	}
+	var c cursor
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor: %w", err)
+	}
+	if len(c.Values) != len(fields) {
+		return nil, fmt.Errorf("decode cursor: the cursor does not match the order of the query")
+	}
+
+	ti := getTypeInfo(new(T))
+	result := &decodedCursor{backward: c.Backward}
+	for i, index := range fields {
+		value := reflect.New(reflect.TypeOf(ti.Values[index]).Elem())
+		err = json.Unmarshal(c.Values[i], value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("decode cursor: %w", err)
+		}
+		value = value.Elem()
+		if value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		result.values = append(result.values, value.Interface())
+	}
+	return result, nil
+}
@@ -1,15 +1,17 @@
 package si
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
-
-	"github.com/google/uuid"
 )
 
 type Q[T Modeler] struct {
 	withs       []func(m T, r []T) error
+	loads       []string
+	aggregates  []func(ctx context.Context, db DB, results []T) error
 	withDeleted bool
 
 	selects    []string
@@ -20,11 +22,22 @@ type Q[T Modeler] struct {
 	orderBy []orderBy
 	take    int
 	skip    int
+	// partition is the column that `Take` and `Skip` are applied to per value, instead of on the whole result.
+	partition string
+	// group is a column from a join that is selected after the columns of T, and scanned into the value from `groupScan`.
+	// It is used when the objects are grouped by a column that is not on T, e.g. the key of F in a pivot table.
+	group     string
+	groupScan func() any
+	// warnings are logged by the client that executes the query, since it is not known when the query is built.
+	warnings []string
+	// alias is the name of the table of T in the query, if it is set, e.g. in a sub query on the same table as the outer query.
+	alias string
 
 	havings  []filter
 	groupBys []string
 
 	//Used during building
+	client      *Client
 	argsCounter int
 	args        []any
 }
@@ -35,43 +48,124 @@ type Q[T Modeler] struct {
 
 // Get will Execute the query and return a list of the result.
 func (q *Q[T]) Get(db DB) ([]T, error) {
-	query := q.buildSelect()
-	log(query, q.args)
-	rows, err := db.Query(query, q.args...)
+	return q.GetContext(context.Background(), db)
+}
+
+// GetContext is same as Get, but with a context.
+func (q *Q[T]) GetContext(ctx context.Context, db DB) ([]T, error) {
+	result := []T{}
+	err := q.iterate(ctx, db, "get", func(row T) error {
+		result = append(result, row)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("si.get: execute query: %w", err)
+		return nil, err
 	}
-	defer func() { _ = rows.Close() }()
 
-	result := &[]T{}
-	for rows.Next() {
-		row := new(T)
-		ti := getTypeInfo(row)
-		var err error
-		if len(q.selects) > 0 {
-			q.selectScan(func(scan ...any) {
-				err = rows.Scan(scan...)
-			})
-		} else {
-			err = rows.Scan(ti.Values...)
-		}
+	err = q.executeWith(ctx, db, result)
+	if err != nil {
+		return nil, err
+	}
+	err = q.afterFind(ctx, db, result)
+	if err != nil {
+		return nil, fmt.Errorf("si.get: %w", err)
+	}
+	return result, nil
+}
+
+// Each will execute the query and call `f` with one row at a time, without keeping the whole result in memory.
+// The relations from `With` are not loaded, use `Chunk` for that.
+// If `f` returns an error, the iteration is stopped and the error is returned.
+func (q *Q[T]) Each(db DB, f func(m T) error) error {
+	return q.EachContext(context.Background(), db, f)
+}
+
+// EachContext is same as Each, but with a context.
+func (q *Q[T]) EachContext(ctx context.Context, db DB, f func(m T) error) error {
+	return q.iterate(ctx, db, "each", func(row T) error {
+		rows := []T{row}
+		err := q.afterFind(ctx, db, rows)
 		if err != nil {
-			return nil, fmt.Errorf("si.get: scan: %w", err)
+			return fmt.Errorf("si.each: %w", err)
 		}
-		reflect.ValueOf(result).Elem().Set(reflect.Append(reflect.ValueOf(result).Elem(), reflect.ValueOf(row).Elem()))
-	}
+		return f(rows[0])
+	})
+}
 
-	err = q.executeWith(*result)
+// Chunk will execute the query and call `f` with `size` rows at a time, with the relations from `With` loaded.
+// Each chunk is a query ordered by the `OrderBy` columns and then the primary key, which starts after the last row of the previous chunk, like `Paginate`.
+// So the `OrderBy` columns must be columns on the model, and should not be nullable.
+// If `f` returns an error, the iteration is stopped and the error is returned.
+func (q *Q[T]) Chunk(db DB, size int, f func(chunk []T) error) error {
+	return q.ChunkContext(context.Background(), db, size, f)
+}
+
+// ChunkContext is same as Chunk, but with a context.
+func (q *Q[T]) ChunkContext(ctx context.Context, db DB, size int, f func(chunk []T) error) error {
+	if size < 1 {
+		return fmt.Errorf("si.chunk: invalid size %d", size)
+	}
+	orders := q.cursorOrder()
+	fields, err := cursorFields[T](orders)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("si.chunk: %w", err)
+	}
+
+	cq := *q
+	cq.orderBy = orders
+	for done := 0; ; done += size {
+		take := size
+		if q.take > 0 {
+			take = min(size, q.take-done)
+			if take <= 0 {
+				return nil
+			}
+		}
+		cq.take = take
+
+		// The rows are closed before the relations are loaded, so it works with only one connection.
+		chunk := make([]T, 0, take)
+		err := cq.iterate(ctx, db, "chunk", func(row T) error {
+			chunk = append(chunk, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+		err = q.executeWith(ctx, db, chunk)
+		if err != nil {
+			return err
+		}
+		err = q.afterFind(ctx, db, chunk)
+		if err != nil {
+			return fmt.Errorf("si.chunk: %w", err)
+		}
+		err = f(chunk)
+		if err != nil {
+			return err
+		}
+		if len(chunk) < take {
+			return nil
+		}
+
+		// `Skip` is only for the first chunk, the next one starts after the last row of this one.
+		cq.skip = 0
+		cq.filters = append(slices.Clone(q.filters), filter{Separator: "AND", Sub: seekFilters(orders, seekValues(chunk[len(chunk)-1], fields))})
 	}
-	return *result, nil
 }
 
 // First will execute the query and return the first element of the result
 func (q *Q[T]) First(db DB) (*T, error) {
+	return q.FirstContext(context.Background(), db)
+}
+
+// FirstContext is same as First, but with a context.
+func (q *Q[T]) FirstContext(ctx context.Context, db DB) (*T, error) {
 	q.take = 1
-	result, err := q.Get(db)
+	result, err := q.GetContext(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("si.first: %w", err)
 	}
@@ -83,18 +177,38 @@ func (q *Q[T]) First(db DB) (*T, error) {
 
 // Find will return the one element in the query result.
 // This will be successful IFF there was one result.
-// The variadic parameter `id` is used to make it optional. If present, only the first element is used.
-func (q *Q[T]) Find(db DB, id ...uuid.UUID) (*T, error) {
+// The variadic parameter `id` is used to make it optional. If present, it is the value of the primary key,
+// or one value for each column of a composite key.
+func (q *Q[T]) Find(db DB, id ...any) (*T, error) {
+	return q.FindContext(context.Background(), db, id...)
+}
+
+// FindContext is same as Find, but with a context.
+func (q *Q[T]) FindContext(ctx context.Context, db DB, id ...any) (*T, error) {
 	if len(id) >= 1 {
-		q = q.Where("id", "=", id[0])
+		columns := keyColumns[T]()
+		if len(id) != len(columns) {
+			return nil, fmt.Errorf("si.find: expected %d values for the primary key of '%s', got %d", len(columns), (*new(T)).GetTable(), len(id))
+		}
+		q.filters = append(q.filters, keyFilters(qualify((*new(T)).GetTable(), columns), id)...)
 	}
-	result, err := q.Get(db)
+	// Two rows are enough to know that there are more than one.
+	q.take = 2
+	result, err := q.GetContext(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("si.find: %w", err)
 	}
 
-	if len(result) != 1 {
-		return nil, ResourceNotFound()
+	table := (*new(T)).GetTable()
+	if len(result) == 0 {
+		return nil, ResourceNotFoundError{Table: table, Filters: describeFilters(q.filters)}
+	}
+	if len(result) > 1 {
+		count, err := q.CountContext(ctx, db)
+		if err != nil {
+			return nil, fmt.Errorf("si.find: %w", err)
+		}
+		return nil, MultipleResultsError{Table: table, Filters: describeFilters(q.filters), Count: count}
 	}
 	return &result[0], nil
 }
@@ -118,7 +232,7 @@ func (q *Q[T]) MustFirst(db DB) *T {
 }
 
 // MustFind is same as Find, but will panic on error.
-func (q *Q[T]) MustFind(db DB, id ...uuid.UUID) *T {
+func (q *Q[T]) MustFind(db DB, id ...any) *T {
 	result, err := q.Find(db, id...)
 	if err != nil {
 		panic(err)
@@ -126,7 +240,59 @@ func (q *Q[T]) MustFind(db DB, id ...uuid.UUID) *T {
 	return result
 }
 
-func (q *Q[T]) executeWith(results []T) error {
+// iterate will execute the query and scan the rows one at a time into `f`.
+// Errors from `f` are returned as they are.
+func (q *Q[T]) iterate(ctx context.Context, db DB, name string, f func(row T) error) error {
+	c := clientOf(db)
+	for _, w := range q.warnings {
+		c.log(w)
+	}
+	query := q.buildSelect(c)
+	rows, err := c.query(ctx, db, query, q.args...)
+	if err != nil {
+		return fmt.Errorf("si.%s: execute query: %w", name, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	info := getModelInfo(reflect.TypeOf((*T)(nil)).Elem())
+	for rows.Next() {
+		row := new(T)
+		var err error
+		if len(q.selects) > 0 {
+			q.selectScan(func(scan ...any) {
+				err = rows.Scan(scan...)
+			})
+		} else if q.group != "" {
+			err = rows.Scan(append(info.values(reflect.ValueOf(row)), q.groupScan())...)
+		} else {
+			err = rows.Scan(info.values(reflect.ValueOf(row))...)
+		}
+		if err != nil {
+			return fmt.Errorf("si.%s: scan: %w", name, err)
+		}
+		if len(q.selects) == 0 {
+			takeSnapshot(reflect.ValueOf(row), nil)
+		}
+		err = f(*row)
+		if err != nil {
+			return err
+		}
+	}
+	if err := rowsErr(rows); err != nil {
+		return fmt.Errorf("si.%s: scan: %w", name, err)
+	}
+	return nil
+}
+
+// afterFind will call the AfterFind hook on the results, unless they are scanned into other values with `Select`.
+func (q *Q[T]) afterFind(ctx context.Context, db DB, results []T) error {
+	if len(q.selects) > 0 {
+		return nil
+	}
+	return runHooks(ctx, db, pointers(results), afterFind)
+}
+
+func (q *Q[T]) executeWith(ctx context.Context, db DB, results []T) error {
 	for _, with := range q.withs {
 		var dummy T
 		err := with(dummy, results)
@@ -134,6 +300,20 @@ func (q *Q[T]) executeWith(results []T) error {
 			return err
 		}
 	}
+	if len(q.loads) > 0 && len(results) > 0 {
+		err := loadPaths(ctx, db, results, splitPaths(q.loads))
+		if err != nil {
+			return err
+		}
+	}
+	if len(results) > 0 {
+		for _, aggregate := range q.aggregates {
+			err := aggregate(ctx, db, results)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -152,9 +332,37 @@ type filter struct {
 
 type Raw string
 
+// subQuerier is a filter value that is built as a sub query.
+// `argsCounter` is the number of arguments before the sub query, and the arguments of the sub query are returned.
+type subQuerier interface {
+	buildSubQuery(c *Client, argsCounter int) (string, []any)
+}
+
+// subQuery is a sub query that is built by a function, with its arguments added by `arg`.
+type subQuery func(d Dialect, arg func(value any) string) string
+
+func (s subQuery) buildSubQuery(c *Client, argsCounter int) (string, []any) {
+	var args []any
+	query := s(c.dialect, func(value any) string {
+		args = append(args, value)
+		return c.dialect.Placeholder(argsCounter + len(args))
+	})
+	return query, args
+}
+
+// buildSubQuery will build the query as a sub query, which selects the `Select` columns or `1`.
+func (q *Q[T]) buildSubQuery(c *Client, argsCounter int) (string, []any) {
+	selects := "1"
+	if len(q.selects) > 0 {
+		selects = strings.Join(q.selects, ",")
+	}
+	query := "SELECT " + selects + q.buildFrom(c, argsCounter)
+	return query, q.args
+}
+
 func (q *Q[T]) Select(selects []string, selectScan func(scan func(...any))) *Q[T] {
 	if len(q.selects) > 0 {
-		log("Select values are already set. Ignoring new values.")
+		q.warnings = append(q.warnings, "Select values are already set. Ignoring new values.")
 		return q
 	}
 	q.selects = selects
@@ -177,6 +385,11 @@ type JoinConf struct {
 	Alias    string
 	// Extra condition?
 	Condition []filter // func(q *Q[T]) *Q[T]
+
+	// through is a join that must be made before this one, e.g. a pivot table.
+	through *JoinConf
+	// withoutDeletedAt is set for tables that do not have a deleted_at, e.g. a pivot table.
+	withoutDeletedAt bool
 }
 
 // Join adds a join on the query. Can be used with `join` a `Relation` to automate the condition.
@@ -205,6 +418,21 @@ func (q *Q[T]) WhereF(f func(q *Q[T]) *Q[T]) *Q[T] {
 	return q
 }
 
+// WhereHas adds a condition that there is at least one related object in the relation from `f`, separated by `AND`.
+// The filters on the relation are used, e.g. `m.Tracks().Where("length", ">", 600)`.
+func (q *Q[T]) WhereHas(f func(m T) Relationship) *Q[T] {
+	var m T
+	q.filters = append(q.filters, filter{Operation: "EXISTS", Value: f(m).exists(), Separator: "AND"})
+	return q
+}
+
+// WhereDoesntHave adds a condition that there are no related objects in the relation from `f`, separated by `AND`.
+func (q *Q[T]) WhereDoesntHave(f func(m T) Relationship) *Q[T] {
+	var m T
+	q.filters = append(q.filters, filter{Operation: "NOT EXISTS", Value: f(m).exists(), Separator: "AND"})
+	return q
+}
+
 // OrWhereF add a condition in parentheses, separated by `OR`
 func (q *Q[T]) OrWhereF(f func(q *Q[T]) *Q[T]) *Q[T] {
 	subQ := &Q[T]{}
@@ -271,16 +499,42 @@ func (q *Q[T]) With(f func(m T, r []T) error) *Q[T] {
 	return q
 }
 
+// Load will retrieve relations by their name, while getting the main object(s).
+// The name is the method on the model that returns the relation, and nested relations are separated by a dot, e.g. `Albums.Tracks`.
+// Each relation is loaded with one query for all objects.
+func (q *Q[T]) Load(paths ...string) *Q[T] {
+	q.loads = append(q.loads, paths...)
+	return q
+}
+
+// WithCount will count the related objects of the relation from `f`, while getting the main object(s), without loading them.
+// The count is returned by `Count` on the relation. This will execute one query for all objects.
+func (q *Q[T]) WithCount(f func(m T) Relationship) *Q[T] {
+	return q.withAggregate(f, "COUNT", "*")
+}
+
+// WithSum will sum `column` of the related objects of the relation from `f`, while getting the main object(s), without loading them.
+// The sum is returned by `Sum` on the relation. This will execute one query for all objects.
+func (q *Q[T]) WithSum(f func(m T) Relationship, column string) *Q[T] {
+	return q.withAggregate(f, "SUM", column)
+}
+
+func (q *Q[T]) withAggregate(f func(m T) Relationship, function, column string) *Q[T] {
+	q.aggregates = append(q.aggregates, func(ctx context.Context, db DB, results []T) error {
+		return f(results[0]).aggregate(ctx, db, results, function, column)
+	})
+	return q
+}
+
 // WithDeleted will ignore the deleted timestamp.
+// This does nothing if deleted_at is disabled on the client.
 func (q *Q[T]) WithDeleted() *Q[T] {
-	if !config.useDeletedAt {
-		log("WithDeleted does nothing if its disabled.")
-	}
 	q.withDeleted = true
 	return q
 }
 
-func (q *Q[T]) buildSelect() string {
+func (q *Q[T]) buildSelect(c *Client) string {
+	d := c.dialect
 	specialSelect := len(q.selects) > 0
 	t := new(T)
 	table := (*t).GetTable()
@@ -291,42 +545,25 @@ func (q *Q[T]) buildSelect() string {
 		query += strings.Join(q.selects, ",")
 	} else {
 		var list []string
-		for _, c := range getTypeInfo(t).Columns {
-			list = append(list, fmt.Sprintf("%s.%s", table, c))
+		for _, c := range getModelInfo(reflect.TypeOf(t).Elem()).Columns {
+			list = append(list, d.Quote(table+"."+c))
 		}
-		query += strings.Join(list, ",")
-	}
-
-	// From
-	query += fmt.Sprintf(" FROM %s", table)
-
-	// Joins
-	for _, jf := range q.joins {
-		j := jf(*t)
-		if config.useDeletedAt && !q.withDeleted {
-			j.Condition = append(j.Condition, filter{Column: j.Table + ".deleted_at", Operation: "IS", Value: nil, Separator: "AND"})
+		if q.partition != "" && (q.take > 0 || q.skip > 0) {
+			inner, outer := list, list
+			if q.group != "" {
+				inner = append(slices.Clone(list), q.group+" AS si_group")
+				outer = append(slices.Clone(list), d.Quote(table+".si_group"))
+			}
+			return q.buildPartitionSelect(c, strings.Join(outer, ","), strings.Join(inner, ","))
 		}
-		condition := q.buildFilters(j.Condition)
-		query += fmt.Sprintf(" %s JOIN %s ON%s", j.JoinType, j.Table, condition)
-	}
-
-	// With Deleted
-	if config.useDeletedAt && !q.withDeleted {
-		otherFilters := q.filters
-		q.filters = []filter{{Column: table + ".deleted_at", Operation: "IS", Value: nil}}
-		if len(otherFilters) > 0 {
-			q.filters = append(q.filters, filter{
-				Separator: "AND",
-				Sub:       otherFilters,
-			})
+		if q.group != "" {
+			list = append(list, q.group+" AS si_group")
 		}
+		query += strings.Join(list, ",")
 	}
 
-	// Where
-	if len(q.filters) > 0 {
-		filterSql := q.buildFilters(q.filters)
-		query += fmt.Sprintf(" WHERE%s", filterSql)
-	}
+	// From, Joins and Where
+	query += q.buildFrom(c, 0)
 
 	// Group By
 	if len(q.groupBys) > 0 && specialSelect {
@@ -341,33 +578,132 @@ func (q *Q[T]) buildSelect() string {
 
 	// Order by
 	if len(q.orderBy) > 0 {
-		query += " ORDER BY "
-		for i, by := range q.orderBy {
-			if i != 0 {
-				query += ", "
-			}
-			query += fmt.Sprintf("%s ", by.Column)
-			if by.Ascending {
-				query += "asc "
-			} else {
-				query += "desc"
-			}
-		}
+		query += " ORDER BY " + q.buildOrderBy()
 	}
 
-	// Limit
+	// Limit and Offset
+	query += d.LimitOffset(q.take, q.skip)
+
+	return query
+}
+
+// buildPartitionSelect will build a select where `Take` and `Skip` are applied for each value of the partition column.
+// `columns` are selected from the `inner` columns of the query, which also has the row number.
+func (q *Q[T]) buildPartitionSelect(c *Client, columns, inner string) string {
+	table := (*new(T)).GetTable()
+	over := "PARTITION BY " + q.partition
+	if len(q.orderBy) > 0 {
+		over += " ORDER BY " + q.buildOrderBy()
+	}
+	query := fmt.Sprintf(
+		"SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (%s) AS si_row%s) AS %s WHERE si_row > %d",
+		columns, inner, over, q.buildFrom(c, 0), c.dialect.Quote(table), q.skip,
+	)
 	if q.take > 0 {
-		query += fmt.Sprintf(" LIMIT %d ", q.take)
+		query += fmt.Sprintf(" AND si_row <= %d", q.skip+q.take)
+	}
+	return query + " ORDER BY si_row"
+}
+
+func (q *Q[T]) buildOrderBy() string {
+	var query string
+	for i, by := range q.orderBy {
+		if i != 0 {
+			query += ", "
+		}
+		query += fmt.Sprintf("%s ", by.Column)
+		if by.Ascending {
+			query += "asc "
+		} else {
+			query += "desc"
+		}
 	}
+	return query
+}
+
+// buildFrom will build the `FROM`, `JOIN` and `WHERE` part of the query.
+// This is shared between all selects, so the arguments are reset here, to start after `argsCounter`.
+func (q *Q[T]) buildFrom(c *Client, argsCounter int) string {
+	t := new(T)
+	table := (*t).GetTable()
+	d := c.dialect
+	q.client = c
+	q.args = nil
+	q.argsCounter = argsCounter
 
-	// Offset
-	if q.skip > 0 {
-		query += fmt.Sprintf(" OFFSET %d ", q.skip)
+	// From
+	query := fmt.Sprintf(" FROM %s", d.Quote(table))
+	if q.alias != "" {
+		query += fmt.Sprintf(" AS %s", d.Quote(q.alias))
+		table = q.alias
 	}
 
+	// Joins
+	for _, jf := range q.joins {
+		query += q.buildJoin(jf(*t))
+	}
+
+	// With Deleted
+	filters := q.filters
+	if c.useDeletedAt && !q.withDeleted {
+		filters = []filter{{Column: d.Quote(table + ".deleted_at"), Operation: "IS", Value: nil}}
+		if len(q.filters) > 0 {
+			filters = append(filters, filter{
+				Separator: "AND",
+				Sub:       q.filters,
+			})
+		}
+	}
+
+	// Where
+	if len(filters) > 0 {
+		filterSql := q.buildFilters(filters)
+		query += fmt.Sprintf(" WHERE%s", filterSql)
+	}
+
+	return query
+}
+
+func (q *Q[T]) buildJoin(j *JoinConf) string {
+	var query string
+	if j.through != nil {
+		query += q.buildJoin(j.through)
+	}
+	table, name := j.Table, j.Table
+	if j.Alias != "" {
+		table, name = fmt.Sprintf("%s AS %s", j.Table, j.Alias), j.Alias
+	}
+	if q.client.useDeletedAt && !q.withDeleted && !j.withoutDeletedAt {
+		j.Condition = append(j.Condition, filter{Column: name + ".deleted_at", Operation: "IS", Value: nil, Separator: "AND"})
+	}
+	condition := q.buildFilters(j.Condition)
+	query += fmt.Sprintf(" %s JOIN %s ON%s", j.JoinType, table, condition)
 	return query
 }
 
+// describeFilters will describe the filters with their values, for error messages.
+func describeFilters(filters []filter) string {
+	var result string
+	for i, f := range filters {
+		if i != 0 {
+			result += fmt.Sprintf(" %s ", f.Separator)
+		}
+		switch {
+		case f.Sub != nil:
+			result += fmt.Sprintf("(%s)", describeFilters(f.Sub))
+		case f.Operation == "IS" && f.Value == nil:
+			result += fmt.Sprintf("%s IS NULL", f.Column)
+		default:
+			if _, ok := f.Value.(subQuerier); ok {
+				result += strings.TrimSpace(fmt.Sprintf("%s %s (...)", f.Column, f.Operation))
+				continue
+			}
+			result += fmt.Sprintf("%s %s %v", f.Column, f.Operation, f.Value)
+		}
+	}
+	return result
+}
+
 func (q *Q[T]) buildFilters(filters []filter) string {
 	var query string
 	for i, f := range filters {
@@ -388,28 +724,45 @@ func (q *Q[T]) buildFilters(filters []filter) string {
 			continue
 		}
 
+		// Handle sub query
+		if sub, ok := f.Value.(subQuerier); ok {
+			subSql, args := sub.buildSubQuery(q.client, q.argsCounter)
+			q.args = append(q.args, args...)
+			q.argsCounter += len(args)
+			if f.Column == "" {
+				query += fmt.Sprintf(" %s (%s)", f.Operation, subSql)
+				continue
+			}
+			query += fmt.Sprintf(" %s %s (%s)", f.Column, f.Operation, subSql)
+			continue
+		}
+
 		// Handle Raw condition
 		if _, ok := f.Value.(Raw); ok {
-			query += fmt.Sprintf(" %s %s %s", f.Column, f.Operation, f.Value)
+			query += fmt.Sprintf(" %s %s %s", f.Column, q.client.dialect.Operator(f.Operation), f.Value)
 			continue
 		}
 
 		// Handle IN list
 		parameters := []string{}
 		if f.Operation == "IN" {
-			for _, elem := range f.Value.([]string) {
-				q.args = append(q.args, elem)
-				q.argsCounter += 1
-				parameters = append(parameters, fmt.Sprintf("$%d", q.argsCounter))
+			list := reflect.ValueOf(f.Value)
+			for i := 0; i < list.Len(); i++ {
+				parameters = append(parameters, q.arg(list.Index(i).Interface()))
 			}
 			query += fmt.Sprintf(" %s IN (%s)", f.Column, strings.Join(parameters, ","))
 			continue
 		}
 
-		q.args = append(q.args, f.Value)
 		// Default condition handling.
-		q.argsCounter += 1
-		query += fmt.Sprintf(" %s %s $%d", f.Column, f.Operation, q.argsCounter)
+		query += fmt.Sprintf(" %s %s %s", f.Column, q.client.dialect.Operator(f.Operation), q.arg(f.Value))
 	}
 	return query
 }
+
+// arg will add an argument to the query, and return its placeholder.
+func (q *Q[T]) arg(value any) string {
+	q.args = append(q.args, value)
+	q.argsCounter += 1
+	return q.client.dialect.Placeholder(q.argsCounter)
+}
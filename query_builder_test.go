@@ -0,0 +1,68 @@
+package si_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/derivatan/si"
+	"github.com/google/uuid"
+)
+
+func TestRowsErr(t *testing.T) {
+	db := &fakeDB{rows: 3, err: context.Canceled}
+
+	result, err := si.Query[benchArtist]().Get(db)
+	if !errors.Is(err, context.Canceled) || result != nil {
+		t.Fatal(err, result)
+	}
+	n := 0
+	err = si.Query[benchArtist]().Each(db, func(a benchArtist) error {
+		n++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || n != 3 {
+		t.Fatal(err, n)
+	}
+	_, err = si.Query[benchArtist]().Count(&fakeDB{err: context.Canceled})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestChunkSeeks(t *testing.T) {
+	db := &fakeDB{rows: 2}
+	var sizes []int
+	err := si.Query[benchArtist]().OrderBy("name", false).Skip(1).Take(5).Chunk(db, 2, func(chunk []benchArtist) error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	})
+	if err != nil || len(sizes) != 3 {
+		t.Fatal(err, sizes)
+	}
+	seek := `WHERE ( ( name < $1) OR ( name = $2 AND artists.id > $3)) ORDER BY name desc, artists.id asc`
+	if !strings.HasSuffix(db.queries[0], "ORDER BY name desc, artists.id asc  LIMIT 2 OFFSET 1") ||
+		!strings.Contains(db.queries[1], seek) || !strings.HasSuffix(db.queries[1], "LIMIT 2") ||
+		!strings.Contains(db.queries[2], seek) || !strings.HasSuffix(db.queries[2], "LIMIT 1") {
+		t.Fatal(db.queries)
+	}
+	if len(db.args[1]) != 3 || db.args[1][0] != "name" {
+		t.Fatal(db.args[1])
+	}
+	if _, ok := db.args[1][2].(uuid.UUID); !ok {
+		t.Fatal(db.args[1])
+	}
+}
+
+func TestSelectWarningOnClient(t *testing.T) {
+	var logs []string
+	db := si.New(si.WithLogger(func(a ...any) { logs = append(logs, fmt.Sprint(a...)) })).DB(&fakeDB{})
+	var name string
+	scan := func(s func(...any)) { s(&name) }
+	_, err := si.Query[benchArtist]().Select([]string{"name"}, scan).Select([]string{"plays"}, scan).Get(db)
+	if err != nil || len(logs) != 2 || !strings.Contains(logs[0], "Select values are already set") || !strings.Contains(logs[1], "SELECT name FROM") {
+		t.Fatal(err, logs)
+	}
+}
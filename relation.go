@@ -1,9 +1,10 @@
 package si
 
 import (
+	"context"
 	"fmt"
-
-	"github.com/google/uuid"
+	"reflect"
+	"strings"
 )
 
 // Relation is the configuration for a relationship between two objects.
@@ -17,8 +18,15 @@ type Relation[F, T Modeler] struct {
 }
 
 type RelationData[T Modeler] struct {
-	loaded bool
-	data   []T
+	loaded     bool
+	data       []T
+	aggregates map[string]float64
+}
+
+// Relationship is implemented by all relations, so they can be used in `WithCount`, `WithSum`, `WhereHas` and `WhereDoesntHave`.
+type Relationship interface {
+	aggregate(ctx context.Context, db DB, result any, function, column string) error
+	exists() subQuerier
 }
 
 func (r *Relation[F, T]) Loaded() bool {
@@ -27,27 +35,93 @@ func (r *Relation[F, T]) Loaded() bool {
 }
 
 func (r *Relation[F, T]) Get(db DB) ([]T, error) {
+	return r.GetContext(context.Background(), db)
+}
+
+func (r *Relation[F, T]) GetContext(ctx context.Context, db DB) ([]T, error) {
 	rd := r.relationData(&r.model)
 	if rd.loaded {
 		return rd.data, nil
 	}
-	return r.innerFilter().Get(db)
+	if r.get == nil {
+		return []T{}, nil
+	}
+	return r.innerFilter().GetContext(ctx, db)
 }
 
 func (r *Relation[F, T]) First(db DB) (*T, error) {
+	return r.FirstContext(context.Background(), db)
+}
+
+func (r *Relation[F, T]) FirstContext(ctx context.Context, db DB) (*T, error) {
 	rd := r.relationData(&r.model)
 	if rd.loaded {
+		if len(rd.data) == 0 {
+			return nil, nil
+		}
 		return &rd.data[0], nil
 	}
-	return r.innerFilter().First(db)
+	if r.get == nil {
+		return nil, nil
+	}
+	return r.innerFilter().FirstContext(ctx, db)
+}
+
+func (r *Relation[F, T]) Find(db DB, id ...any) (*T, error) {
+	return r.FindContext(context.Background(), db, id...)
 }
 
-func (r *Relation[F, T]) Find(db DB, id ...uuid.UUID) (*T, error) {
+func (r *Relation[F, T]) FindContext(ctx context.Context, db DB, id ...any) (*T, error) {
 	rd := r.relationData(&r.model)
 	if rd.loaded {
+		if len(rd.data) == 0 {
+			return nil, ResourceNotFoundError{Table: (*new(T)).GetTable()}
+		}
 		return &rd.data[0], nil
 	}
-	return r.innerFilter().Find(db, id...)
+	if r.get == nil {
+		return nil, ResourceNotFoundError{Table: (*new(T)).GetTable()}
+	}
+	return r.innerFilter().FindContext(ctx, db, id...)
+}
+
+// Count will return the number of related objects.
+// If the count is loaded with `WithCount`, or the objects are loaded, no query is executed.
+func (r *Relation[F, T]) Count(db DB) (int64, error) {
+	return r.CountContext(context.Background(), db)
+}
+
+// CountContext is same as Count, but with a context.
+func (r *Relation[F, T]) CountContext(ctx context.Context, db DB) (int64, error) {
+	rd := r.relationData(&r.model)
+	if count, ok := rd.aggregates[aggregateKey("COUNT", "*")]; ok {
+		return int64(count), nil
+	}
+	if rd.loaded {
+		return int64(len(rd.data)), nil
+	}
+	if r.get == nil {
+		return 0, nil
+	}
+	return r.innerFilter().CountContext(ctx, db)
+}
+
+// Sum will return the sum of `column` of the related objects.
+// If the sum is loaded with `WithSum`, no query is executed.
+func (r *Relation[F, T]) Sum(db DB, column string) (float64, error) {
+	return r.SumContext(context.Background(), db, column)
+}
+
+// SumContext is same as Sum, but with a context.
+func (r *Relation[F, T]) SumContext(ctx context.Context, db DB, column string) (float64, error) {
+	rd := r.relationData(&r.model)
+	if sum, ok := rd.aggregates[aggregateKey("SUM", column)]; ok {
+		return sum, nil
+	}
+	if r.get == nil {
+		return 0, nil
+	}
+	return r.innerFilter().SumContext(ctx, db, column)
 }
 
 func (r *Relation[F, T]) MustGet(db DB) []T {
@@ -66,7 +140,7 @@ func (r *Relation[F, T]) MustFirst(db DB) *T {
 	return result
 }
 
-func (r *Relation[F, T]) MustFind(db DB, id ...uuid.UUID) *T {
+func (r *Relation[F, T]) MustFind(db DB, id ...any) *T {
 	result, err := r.Find(db, id...)
 	if err != nil {
 		panic(err)
@@ -74,6 +148,14 @@ func (r *Relation[F, T]) MustFind(db DB, id ...uuid.UUID) *T {
 	return result
 }
 
+func (r *Relation[F, T]) MustCount(db DB) int64 {
+	result, err := r.Count(db)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
 func (r *Relation[F, T]) innerFilter() *Q[T] {
 	result := r.get
 	if len(r.query.filters) > 0 {
@@ -81,6 +163,9 @@ func (r *Relation[F, T]) innerFilter() *Q[T] {
 			return r.query
 		})
 	}
+	result.orderBy = r.query.orderBy
+	result.take = r.query.take
+	result.skip = r.query.skip
 	return result
 }
 
@@ -130,64 +215,296 @@ func (r *Relation[F, T]) WithDeleted() *Relation[F, T] {
 }
 
 func (r *Relation[F, T]) Execute(db DB, result []F) error {
+	return r.ExecuteContext(context.Background(), db, result)
+}
+
+func (r *Relation[F, T]) ExecuteContext(ctx context.Context, db DB, result []F) error {
 	if len(result) < 1 {
 		return nil
 	}
-	var ids []string
+	m, err := r.load(ctx, db, result)
+	if err != nil {
+		return err
+	}
+	for i := range result {
+		rd := r.relationData(&result[i])
+		rd.data = nil
+		if id, ok := r.relationType.collectID(result[i]); ok {
+			rd.data = m[newKey(id)]
+		}
+		rd.loaded = true
+	}
+	return nil
+}
+
+// load will get the related objects for all of `result`, grouped by the id from `collectID`.
+// `OrderBy`, `Take` and `Skip` on the relation are applied to each group.
+func (r *Relation[F, T]) load(ctx context.Context, db DB, result []F) (map[any][]T, error) {
+	var ids [][]any
 	for _, r2 := range result {
-		ids = append(ids, r.relationType.collectID(r2).String())
+		if id, ok := r.relationType.collectID(r2); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return map[any][]T{}, nil
+	}
+
+	query := Query[T]()
+	query.orderBy = r.query.orderBy
+	query.take = r.query.take
+	query.skip = r.query.skip
+	if loader, ok := r.relationType.(relationLoader[F, T]); ok {
+		if len(r.query.filters) > 0 {
+			query = query.WhereF(func(q *Q[T]) *Q[T] {
+				return r.query
+			})
+		}
+		var keys []any
+		for _, id := range ids {
+			keys = append(keys, newKey(id))
+		}
+		return loader.load(ctx, db, query, keys)
 	}
 
-	query := Query[T]().Where(r.relationType.queryColumn(), "IN", ids)
+	columns := r.relationType.queryColumns()
+	query.partition = strings.Join(columns, ", ")
+	query.filters = append(query.filters, keysFilter(columns, ids))
 	if len(r.query.filters) > 0 {
 		query = query.WhereF(func(q *Q[T]) *Q[T] {
 			return r.query
 		})
 	}
 
-	related, err := query.Get(db)
+	related, err := query.GetContext(ctx, db)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	m := map[uuid.UUID][]T{}
+	m := map[any][]T{}
 	for _, r2 := range related {
-		group := r.relationType.groupBy(r2)
-		m[r.relationType.groupBy(r2)] = append(m[group], r2)
+		group := newKey(r.relationType.groupBy(r2))
+		m[group] = append(m[group], r2)
+	}
+	return m, nil
+}
+
+// aggregate will calculate `function` of `column` on the related objects, for all of `result`, and store it in the relation data.
+// It is one query with a correlated sub query for each object.
+func (r *Relation[F, T]) aggregate(ctx context.Context, db DB, result any, function, column string) error {
+	models := result.([]F)
+	var ids [][]any
+	for _, m := range models {
+		if id, ok := keyValues(m); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	c := clientOf(db)
+	fromTable := (*new(F)).GetTable()
+	keys := qualify(fromTable, keyColumns[F]())
+	var selects []string
+	for _, key := range keys {
+		selects = append(selects, c.dialect.Quote(key))
+	}
+	sub := r.correlated()
+	sub.selects = []string{fmt.Sprintf("%s(%s)", function, column)}
+	subSql, args := sub.buildSubQuery(c, 0)
+	outer := Query[F]().WithDeleted()
+	outer.filters = append(outer.filters, keysFilter(keys, ids))
+	query := fmt.Sprintf("SELECT %s, (%s)", strings.Join(selects, ","), subSql) + outer.buildFrom(c, len(args))
+	args = append(args, outer.args...)
+
+	rows, err := c.query(ctx, db, query, args...)
+	if err != nil {
+		return fmt.Errorf("si.aggregate: execute query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	info := getModelInfo(reflect.TypeOf((*F)(nil)).Elem())
+	values := map[any]float64{}
+	for rows.Next() {
+		var dest []any
+		for _, index := range info.key {
+			t := reflect.TypeOf((*F)(nil)).Elem().FieldByIndex(info.indexes[index]).Type
+			dest = append(dest, reflect.New(t).Interface())
+		}
+		var value *float64
+		err = rows.Scan(append(dest, &value)...)
+		if err != nil {
+			return fmt.Errorf("si.aggregate: scan: %w", err)
+		}
+		var id []any
+		for _, d := range dest {
+			v, _ := keyPart(reflect.ValueOf(d).Elem(), len(dest))
+			id = append(id, v)
+		}
+		if value != nil {
+			values[newKey(id)] = *value
+		}
+	}
+	if err := rowsErr(rows); err != nil {
+		return fmt.Errorf("si.aggregate: scan: %w", err)
 	}
-	for i := range result {
-		rd := RelationData[T]{}
-		rd.data = m[r.relationType.collectID(result[i])]
-		rd.loaded = true
 
-		a := r.relationData(&result[i])
-		*a = rd
+	key := aggregateKey(function, column)
+	for i := range models {
+		rd := r.relationData(&models[i])
+		if rd.aggregates == nil {
+			rd.aggregates = map[string]float64{}
+		}
+		if id, ok := keyValues(models[i]); ok {
+			rd.aggregates[key] = values[newKey(id)]
+		}
 	}
 	return nil
 }
 
+// exists is the sub query for `WhereHas`, which selects the related objects of the outer table of F.
+func (r *Relation[F, T]) exists() subQuerier {
+	return r.correlated()
+}
+
+// correlated will return a query for the related objects of the outer table of F, to be used as a sub query.
+// The condition from `Join` is in the where clause, and the tables it goes through are in an `EXISTS`,
+// so the filters on the relation only see the columns of T.
+// If F and T are the same table, the table of T is aliased, so the condition refers to the outer table.
+func (r *Relation[F, T]) correlated() *Q[T] {
+	q := Query[T]()
+	toTable := (*new(T)).GetTable()
+	if toTable == (*new(F)).GetTable() {
+		q.alias = "si_" + toTable
+		toTable = q.alias
+	}
+	j := r.join(INNER, toTable)
+	q.withDeleted = r.query.withDeleted
+	if j.through != nil {
+		q.filters = append(q.filters, filter{Operation: "EXISTS", Value: correlatedThrough[T]{join: j.through, condition: j.Condition, withDeleted: q.withDeleted}, Separator: "AND"})
+	} else {
+		q.filters = append(q.filters, filter{Separator: "AND", Sub: j.Condition})
+	}
+	if len(r.query.filters) > 0 {
+		q.filters = append(q.filters, filter{Separator: "AND", Sub: r.query.filters})
+	}
+	return q
+}
+
+// correlatedThrough is a sub query on a table that a relation goes through, e.g. a pivot table.
+// `condition` connects it to the table before it.
+type correlatedThrough[T Modeler] struct {
+	join        *JoinConf
+	condition   []filter
+	withDeleted bool
+}
+
+func (ct correlatedThrough[T]) buildSubQuery(c *Client, argsCounter int) (string, []any) {
+	q := &Q[T]{client: c, argsCounter: argsCounter, withDeleted: ct.withDeleted}
+	filters := append([]filter{}, ct.condition...)
+	if c.useDeletedAt && !ct.withDeleted && !ct.join.withoutDeletedAt {
+		filters = append(filters, filter{Column: ct.join.Table + ".deleted_at", Operation: "IS", Value: nil, Separator: "AND"})
+	}
+	if ct.join.through != nil {
+		filters = append(filters, filter{Operation: "EXISTS", Value: correlatedThrough[T]{join: ct.join.through, condition: ct.join.Condition, withDeleted: ct.withDeleted}, Separator: "AND"})
+	} else {
+		filters = append(filters, ct.join.Condition...)
+	}
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE%s", ct.join.Table, q.buildFilters(filters))
+	return query, q.args
+}
+
+func aggregateKey(function, column string) string {
+	return fmt.Sprintf("%s(%s)", function, column)
+}
+
 func (r *Relation[F, T]) Join(joinType JoinType) *JoinConf {
-	f := new(F)
-	t := new(T)
-	j1, j2 := r.relationType.joinColumns()
-	return &JoinConf{
-		JoinType: joinType,
-		Table:    (*t).GetTable(),
-		Alias:    "",
-		Condition: []filter{
-			{
-				Column:    fmt.Sprintf("%s.%s", (*f).GetTable(), j1),
+	return r.join(joinType, (*new(T)).GetTable())
+}
+
+// join is same as Join, but `toTable` is the name of the table of T in the condition, which is an alias if it is not the table.
+func (r *Relation[F, T]) join(joinType JoinType, toTable string) *JoinConf {
+	var j *JoinConf
+	if joiner, ok := r.relationType.(relationJoiner); ok {
+		j = joiner.join(joinType, toTable)
+	} else {
+		j1, j2 := r.relationType.joinColumns()
+		var condition []filter
+		for i := range j1 {
+			condition = append(condition, filter{
+				Column:    fmt.Sprintf("%s.%s", (*new(F)).GetTable(), j1[i]),
 				Operation: "=",
-				Value:     Raw(fmt.Sprintf("%s.%s", (*t).GetTable(), j2)),
+				Value:     Raw(fmt.Sprintf("%s.%s", toTable, j2[i])),
 				Separator: "AND",
-			},
-		},
+			})
+		}
+		j = &JoinConf{
+			JoinType:  joinType,
+			Table:     (*new(T)).GetTable(),
+			Condition: condition,
+		}
 	}
+	if toTable != j.Table {
+		j.Alias = toTable
+	}
+	return j
 }
 
 type relationType[F, T Modeler] interface {
-	collectID(F) uuid.UUID
-	groupBy(T) uuid.UUID
-	queryColumn() string
-	joinColumns() (string, string)
+	// collectID is the values of `queryColumns` to find the related objects with. It is false if there can be no related objects.
+	collectID(F) ([]any, bool)
+	groupBy(T) []any
+	queryColumns() []string
+	joinColumns() ([]string, []string)
+}
+
+// relationLoader is implemented by the relation types that can not be loaded with `queryColumns` and `groupBy`.
+// They only support a single column, so the `ids` are the values and not a list of values.
+// `query` has the filters, order, take and skip from the relation, and the result is grouped by the ids from `collectID`.
+// Take and skip must be applied to each group with a partition on `query`, e.g. with `loadGroups`.
+type relationLoader[F, T Modeler] interface {
+	load(ctx context.Context, db DB, query *Q[T], ids []any) (map[any][]T, error)
+}
+
+// relationJoiner is implemented by the relation types that can not be joined with `joinColumns`.
+// `toTable` is the name of the table of T in the condition.
+type relationJoiner interface {
+	join(joinType JoinType, toTable string) *JoinConf
+}
+
+// loadGroups will get the objects of `query`, grouped by `group`, which is a column from a join that is scanned as `groupType`.
+// `Take` and `Skip` are applied to each group in the query. An object is in the result once for each group it is in.
+func loadGroups[T Modeler](ctx context.Context, db DB, query *Q[T], group string, groupType reflect.Type) (map[any][]T, error) {
+	var value reflect.Value
+	query.group = group
+	query.groupScan = func() any {
+		value = reflect.New(groupType)
+		return value.Interface()
+	}
+	query.partition = group
+
+	var related []T
+	var groups []any
+	err := query.iterate(ctx, db, "get", func(row T) error {
+		related = append(related, row)
+		groups = append(groups, value.Elem().Interface())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = query.executeWith(ctx, db, related)
+	if err != nil {
+		return nil, err
+	}
+	err = query.afterFind(ctx, db, related)
+	if err != nil {
+		return nil, fmt.Errorf("si.get: %w", err)
+	}
+
+	m := map[any][]T{}
+	for i, r := range related {
+		m[groups[i]] = append(m[groups[i]], r)
+	}
+	return m, nil
 }
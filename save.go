@@ -1,6 +1,8 @@
 package si
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
@@ -10,128 +12,544 @@ import (
 	"github.com/google/uuid"
 )
 
-func save[T Modeler](db DB, m *T, fields []string) error {
+func save[T Modeler](ctx context.Context, db DB, m *T, fields []string) error {
+	if _, ok := keyValues(*m); !ok {
+		now := time.Now()
+		refVal := reflect.ValueOf(m)
+		info := getModelInfo(refVal.Type().Elem())
+		info.setTime(refVal, "updated_at", now)
+		info.setTime(refVal, "created_at", now)
+		return insert[T](ctx, db, m)
+	} else {
+		return update[T](ctx, db, m, fields)
+	}
+}
+
+func insert[T Modeler](ctx context.Context, db DB, m *T) error {
+	err := runHooks(ctx, db, []*T{m}, beforeSave, beforeCreate)
+	if err != nil {
+		return fmt.Errorf("si.insert: %w", err)
+	}
+	err = validate(reflect.ValueOf(m), nil)
+	if err != nil {
+		return fmt.Errorf("si.insert: %w", err)
+	}
+	tis := []typeInfo{getTypeInfo(m)}
+	generated, err := generateIDs[T](clientOf(db), tis)
+	if err != nil {
+		return fmt.Errorf("si.insert: %w", err)
+	}
+	err = insertRows[T](ctx, db, tis, nil)
+	if err != nil {
+		resetIDs(generated)
+		return fmt.Errorf("si.insert: %w", err)
+	}
+	takeSnapshot(reflect.ValueOf(m), nil)
+	err = runHooks(ctx, db, []*T{m}, afterCreate, afterSave)
+	if err != nil {
+		return fmt.Errorf("si.insert: %w", err)
+	}
+	return nil
+}
+
+// upsertConf is the configuration for an insert, that should update the row if it already exists.
+type upsertConf struct {
+	conflictColumns []string
+	updateColumns   []string
+}
+
+// newUpsertConf will update all columns except the primary key, created_at and the conflict columns, if `updateColumns` is nil.
+// updated_at is always updated.
+func newUpsertConf[T Modeler](conflictColumns, updateColumns []string) *upsertConf {
+	if updateColumns == nil {
+		key := keyColumns[T]()
+		for _, column := range getTypeInfo(new(T)).Columns {
+			if !slices.Contains(key, column) && column != "created_at" && !slices.Contains(conflictColumns, column) {
+				updateColumns = append(updateColumns, column)
+			}
+		}
+	}
+	if !slices.Contains(updateColumns, "updated_at") {
+		updateColumns = append(slices.Clip(updateColumns), "updated_at")
+	}
+	return &upsertConf{
+		conflictColumns: conflictColumns,
+		updateColumns:   updateColumns,
+	}
+}
+
+// insertMany will set the timestamps and insert all models, or upsert them if `upsert` is given.
+func insertMany[T Modeler](ctx context.Context, db DB, models []*T, upsert *upsertConf, name string) error {
+	c := clientOf(db)
 	now := time.Now()
-	// Updated at
-	reflect.ValueOf(m).Elem().Field(0).Field(2).Set(reflect.ValueOf(&now))
+	info := getModelInfo(reflect.TypeOf((*T)(nil)).Elem())
+	for _, m := range models {
+		refVal := reflect.ValueOf(m)
+		info.setTime(refVal, "created_at", now)
+		info.setTime(refVal, "updated_at", now)
+	}
+	before, after := []hook{beforeSave, beforeCreate}, []hook{afterCreate, afterSave}
+	if upsert != nil {
+		before, after = []hook{beforeSave}, []hook{afterSave}
+	}
+	err := runHooks(ctx, db, models, before...)
+	if err != nil {
+		return fmt.Errorf("si.%s: %w", name, err)
+	}
+	for _, m := range models {
+		err = validate(reflect.ValueOf(m), nil)
+		if err != nil {
+			return fmt.Errorf("si.%s: %w", name, err)
+		}
+	}
+
+	var tis []typeInfo
+	for _, m := range models {
+		tis = append(tis, getTypeInfo(m))
+	}
+	generated, err := generateIDs[T](c, tis)
+	if err != nil {
+		return fmt.Errorf("si.%s: %w", name, err)
+	}
 
-	if (*m).GetModel().ID == nil {
-		// Created at
-		reflect.ValueOf(m).Elem().Field(0).Field(1).Set(reflect.ValueOf(now))
+	// The rows are grouped by the key columns that are not set, since they are not in the insert.
+	var groups [][]typeInfo
+	groupIndex := map[string]int{}
+	for _, ti := range tis {
+		unset := fmt.Sprint(unsetKeys(ti))
+		index, ok := groupIndex[unset]
+		if !ok {
+			index = len(groups)
+			groupIndex[unset] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], ti)
+	}
 
-		return insert[T](db, m)
-	} else {
-		return update[T](db, m, fields)
+	// Split the rows, so each query is within the parameter limit.
+	maxParameters := c.dialect.MaxParameters()
+	for _, group := range groups {
+		size := max(1, maxParameters/len(group[0].Values))
+		for start := 0; start < len(group); start += size {
+			err = insertRows[T](ctx, db, group[start:min(start+size, len(group))], upsert)
+			if err != nil {
+				resetIDs(generated)
+				return fmt.Errorf("si.%s: %w", name, err)
+			}
+		}
+	}
+	for _, m := range models {
+		takeSnapshot(reflect.ValueOf(m), nil)
+	}
+	err = runHooks(ctx, db, models, after...)
+	if err != nil {
+		return fmt.Errorf("si.%s: %w", name, err)
 	}
+	return nil
 }
 
-func insert[T Modeler](db DB, m *T) error {
-	ti := getTypeInfo(m)
+// insertRows will insert all `tis` in one query, and set the keys that are not set on them.
+// The same key columns must be set on all of them.
+func insertRows[T Modeler](ctx context.Context, db DB, tis []typeInfo, upsert *upsertConf) error {
+	c := clientOf(db)
+	d := c.dialect
 
-	query, parameters := buildInsert[T](ti)
-	log(query, parameters)
+	// The key of an upserted row may be another than the one that is set, so all key columns are returned.
+	returning := unsetKeys(tis[0])
+	if upsert != nil {
+		returning = tis[0].Key
+	}
+	if len(returning) == 0 {
+		query, parameters := buildInsert[T](tis, d, upsert, nil)
+		_, err := c.exec(ctx, db, query, parameters...)
+		if err != nil {
+			return fmt.Errorf("execute query: %w", err)
+		}
+		return nil
+	}
+	if !d.Returning() {
+		return insertWithoutReturning[T](ctx, db, tis, upsert)
+	}
 
-	rows, err := db.Query(query, parameters...)
+	query, parameters := buildInsert[T](tis, d, upsert, returning)
+	rows, err := c.query(ctx, db, query, parameters...)
 	if err != nil {
-		return fmt.Errorf("si.insert: execute query: %w", err)
+		return fmt.Errorf("execute query: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	rows.Next()
-	err = rows.Scan(ti.Values[0])
+	for _, ti := range tis {
+		if !rows.Next() {
+			if err := rowsErr(rows); err != nil {
+				return fmt.Errorf("scan: %w", err)
+			}
+			return errors.New("scan: not all keys were returned")
+		}
+		var dest []any
+		for _, index := range returning {
+			dest = append(dest, ti.Values[index])
+		}
+		err = rows.Scan(dest...)
+		if err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertWithoutReturning will insert the rows when the keys can not be returned from the database.
+// A `uuid.UUID` key is generated here, and an integer key is the last insert id, which needs one query for each row.
+func insertWithoutReturning[T Modeler](ctx context.Context, db DB, tis []typeInfo, upsert *upsertConf) error {
+	c := clientOf(db)
+	d := c.dialect
+	unset := unsetKeys(tis[0])
+
+	var generated []reflect.Value
+	for _, ti := range tis {
+		for _, index := range unset {
+			field := reflect.ValueOf(ti.Values[index]).Elem()
+			if !setUUID(field) {
+				break
+			}
+			generated = append(generated, field)
+		}
+	}
+	if len(generated) == len(tis)*len(unset) {
+		query, parameters := buildInsert[T](tis, d, upsert, nil)
+		_, err := c.exec(ctx, db, query, parameters...)
+		if err != nil {
+			resetIDs(generated)
+			return fmt.Errorf("execute query: %w", err)
+		}
+		if upsert != nil {
+			return selectUpsertedKeys[T](ctx, db, tis, upsert)
+		}
+		return nil
+	}
+	resetIDs(generated)
+
+	if len(unset) > 1 {
+		return fmt.Errorf("the key of '%s' can not be returned by the dialect", (*new(T)).GetTable())
+	}
+	for _, ti := range tis {
+		query, parameters := buildInsert[T]([]typeInfo{ti}, d, upsert, nil)
+		result, err := c.exec(ctx, db, query, parameters...)
+		if err != nil {
+			return fmt.Errorf("execute query: %w", err)
+		}
+		if upsert != nil {
+			err = selectUpsertedKeys[T](ctx, db, []typeInfo{ti}, upsert)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = setLastInsertID(reflect.ValueOf(ti.Values[unset[0]]).Elem(), result)
+		if err != nil {
+			return fmt.Errorf("the key of '%s' can not be returned by the dialect: %w", (*new(T)).GetTable(), err)
+		}
+	}
+	return nil
+}
+
+// setUUID will set a new random uuid on the field, if it is a `uuid.UUID` or `*uuid.UUID`.
+func setUUID(field reflect.Value) bool {
+	switch field.Interface().(type) {
+	case uuid.UUID:
+		field.Set(reflect.ValueOf(uuid.New()))
+	case *uuid.UUID:
+		id := uuid.New()
+		field.Set(reflect.ValueOf(&id))
+	default:
+		return false
+	}
+	return true
+}
+
+// setLastInsertID will set the last insert id from `result` on the field, if it is an integer.
+func setLastInsertID(field reflect.Value, result any) error {
+	r, ok := result.(interface{ LastInsertId() (int64, error) })
+	if !ok {
+		return errors.New("there is no last insert id")
+	}
+	id, err := r.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("si.insert: scan: %w", err)
+		return err
 	}
+	value := field
+	if field.Kind() == reflect.Pointer {
+		value = reflect.New(field.Type().Elem()).Elem()
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("the key is not an integer")
+	}
+	if field.Kind() == reflect.Pointer {
+		field.Set(value.Addr())
+	}
+	return nil
+}
 
+// selectUpsertedKeys will get the keys by the conflict columns, since the key that is set is not used if the row was updated.
+func selectUpsertedKeys[T Modeler](ctx context.Context, db DB, tis []typeInfo, upsert *upsertConf) error {
+	c := clientOf(db)
+	d := c.dialect
+	var columns []string
+	for _, index := range tis[0].Key {
+		columns = append(columns, d.Quote(tis[0].Columns[index]))
+	}
+	for _, ti := range tis {
+		var conditions []string
+		var parameters []any
+		for _, column := range upsert.conflictColumns {
+			index := slices.Index(ti.Columns, column)
+			if index < 0 {
+				return fmt.Errorf("'%s' is not a column on '%s'", column, (*new(T)).GetTable())
+			}
+			parameters = append(parameters, ti.Values[index])
+			conditions = append(conditions, fmt.Sprintf("%s = %s", d.Quote(column), d.Placeholder(len(parameters))))
+		}
+		query := fmt.Sprintf(
+			"SELECT %s FROM %s WHERE %s",
+			strings.Join(columns, ","),
+			d.Quote((*new(T)).GetTable()),
+			strings.Join(conditions, " AND "),
+		)
+
+		err := func() error {
+			rows, err := c.query(ctx, db, query, parameters...)
+			if err != nil {
+				return fmt.Errorf("select key: %w", err)
+			}
+			defer func() { _ = rows.Close() }()
+			if !rows.Next() {
+				if err := rowsErr(rows); err != nil {
+					return fmt.Errorf("select key: %w", err)
+				}
+				return errors.New("select key: the row was not found")
+			}
+			var dest []any
+			for _, index := range ti.Key {
+				dest = append(dest, ti.Values[index])
+			}
+			return rows.Scan(dest...)
+		}()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func buildInsert[T Modeler](ti typeInfo) (string, []any) {
-	var values []string
-	var parameters []any
-	columns := ti.Columns[1:]
+// unsetKeys will return the indexes of the key columns that are not set.
+func unsetKeys(ti typeInfo) []int {
+	var result []int
+	for _, index := range ti.Key {
+		if _, ok := keyPart(reflect.ValueOf(ti.Values[index]).Elem(), len(ti.Key)); !ok {
+			result = append(result, index)
+		}
+	}
+	return result
+}
 
-	for i := 1; i < len(ti.Values); i++ {
-		values = append(values, fmt.Sprintf("$%d", i))
-		parameters = append(parameters, ti.Values[i])
+// buildInsert will build one insert with a row for each of `tis`.
+// The key columns that are not set are left out, and the `returning` columns are returned if the dialect supports it.
+func buildInsert[T Modeler](tis []typeInfo, d Dialect, upsert *upsertConf, returning []int) (string, []any) {
+	unset := unsetKeys(tis[0])
+
+	var columns []string
+	for i, column := range tis[0].Columns {
+		if !slices.Contains(unset, i) {
+			columns = append(columns, d.Quote(column))
+		}
 	}
 
-	if ti.Values[0] != nil {
-		apa := ti.Values[0].(**uuid.UUID)
-		if *apa != nil {
-			values = append(values, fmt.Sprintf("$%d", len(ti.Values)))
-			parameters = append(parameters, *apa)
-			columns = append(columns, "id")
+	var rows []string
+	var parameters []any
+	for _, ti := range tis {
+		var values []string
+		for i := range ti.Values {
+			if slices.Contains(unset, i) {
+				continue
+			}
+			parameters = append(parameters, ti.Values[i])
+			values = append(values, d.Placeholder(len(parameters)))
 		}
+		rows = append(rows, "("+strings.Join(values, ",")+")")
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s) RETURNING id",
-		(*new(T)).GetTable(),
+		"INSERT INTO %s (%s) VALUES %s",
+		d.Quote((*new(T)).GetTable()),
 		strings.Join(columns, ","),
-		strings.Join(values, ","),
+		strings.Join(rows, ","),
 	)
+	if upsert != nil {
+		query += d.Upsert(upsert.conflictColumns, upsert.updateColumns)
+	}
+	if len(returning) > 0 && d.Returning() {
+		var list []string
+		for _, index := range returning {
+			list = append(list, d.Quote(tis[0].Columns[index]))
+		}
+		query += " RETURNING " + strings.Join(list, ",")
+	}
 	return query, parameters
 }
 
-func delete_[T Modeler](db DB, id uuid.UUID) error {
+func delete_[T Modeler](ctx context.Context, db DB, id []any) error {
+	c := clientOf(db)
+	d := c.dialect
+	condition, err := keyCondition[T](d, id)
+	if err != nil {
+		return fmt.Errorf("si.delete: %w", err)
+	}
+	models, err := deleteHooks[T](id)
+	if err != nil {
+		return fmt.Errorf("si.delete: %w", err)
+	}
+	err = runHooks(ctx, db, models, beforeDelete)
+	if err != nil {
+		return fmt.Errorf("si.delete: %w", err)
+	}
 	query := fmt.Sprintf(
-		"UPDATE %s SET deleted_at = now() WHERE id = $1",
-		(*new(T)).GetTable(),
+		"UPDATE %s SET %s = %s WHERE %s",
+		d.Quote((*new(T)).GetTable()),
+		d.Quote("deleted_at"),
+		d.Now(),
+		condition,
 	)
-	log(query, id)
-
-	_, err := db.Exec(query, id)
+	_, err = c.exec(ctx, db, query, id...)
 	if err != nil {
 		return fmt.Errorf("si.delete: execute query: %w", err)
 	}
+	err = runHooks(ctx, db, models, afterDelete)
+	if err != nil {
+		return fmt.Errorf("si.delete: %w", err)
+	}
 	return nil
 }
 
-func deleteHard[T Modeler](db DB, id uuid.UUID) error {
+func deleteHard[T Modeler](ctx context.Context, db DB, id []any) error {
+	c := clientOf(db)
+	d := c.dialect
+	condition, err := keyCondition[T](d, id)
+	if err != nil {
+		return fmt.Errorf("si.deleteHard: %w", err)
+	}
+	models, err := deleteHooks[T](id)
+	if err != nil {
+		return fmt.Errorf("si.deleteHard: %w", err)
+	}
+	err = runHooks(ctx, db, models, beforeDelete)
+	if err != nil {
+		return fmt.Errorf("si.deleteHard: %w", err)
+	}
 	query := fmt.Sprintf(
-		"DELETE FROM %s WHERE id = $1",
-		(*new(T)).GetTable(),
+		"DELETE FROM %s WHERE %s",
+		d.Quote((*new(T)).GetTable()),
+		condition,
 	)
-	log(query, id)
-
-	_, err := db.Exec(query, id)
+	_, err = c.exec(ctx, db, query, id...)
 	if err != nil {
 		return fmt.Errorf("si.deleteHard: execute query: %w", err)
 	}
+	err = runHooks(ctx, db, models, afterDelete)
+	if err != nil {
+		return fmt.Errorf("si.deleteHard: %w", err)
+	}
 	return nil
 }
 
-func update[T Modeler](db DB, m *T, fields []string) error {
-	ti := getTypeInfo(m)
-	query, parameters := buildUpdate[T](ti, fields)
-	log(query, parameters)
+// keyCondition will build the condition for the primary key of T, with the placeholders for `id`.
+func keyCondition[T Modeler](d Dialect, id []any) (string, error) {
+	columns := keyColumns[T]()
+	if len(columns) == 0 {
+		return "", fmt.Errorf("'%s' does not have a primary key", (*new(T)).GetTable())
+	}
+	if len(id) != len(columns) {
+		return "", fmt.Errorf("expected %d values for the primary key of '%s', got %d", len(columns), (*new(T)).GetTable(), len(id))
+	}
+	var conditions []string
+	for i, column := range columns {
+		conditions = append(conditions, fmt.Sprintf("%s = %s", d.Quote(column), d.Placeholder(i+1)))
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+func update[T Modeler](ctx context.Context, db DB, m *T, fields []string) error {
+	err := runHooks(ctx, db, []*T{m}, beforeSave, beforeUpdate)
+	if err != nil {
+		return fmt.Errorf("si.update: %w", err)
+	}
+	refVal := reflect.ValueOf(m)
+	info := getModelInfo(refVal.Type().Elem())
+
+	// A model that is loaded or saved before only updates the changed columns, if `fields` is not given.
+	// Nothing is updated if there are no changes.
+	changed := false
+	if fields == nil {
+		if changes, ok := changesOf(refVal); ok {
+			fields = []string{}
+			for _, column := range info.Columns {
+				if _, ok := changes[column]; ok {
+					fields = append(fields, column)
+				}
+			}
+			changed = len(fields) > 0
+		}
+	}
+	err = validate(refVal, fields)
+	if err != nil {
+		return fmt.Errorf("si.update: %w", err)
+	}
 
-	_, err := db.Exec(query, parameters...)
+	if fields == nil || len(fields) > 0 {
+		info.setTime(refVal, "updated_at", time.Now())
+		if changed && slices.Contains(info.Columns, "updated_at") && !slices.Contains(fields, "updated_at") {
+			fields = append(fields, "updated_at")
+		}
+		c := clientOf(db)
+		ti := getTypeInfo(m)
+		query, parameters := buildUpdate[T](ti, fields, c.dialect)
+		_, err = c.exec(ctx, db, query, parameters...)
+		if err != nil {
+			return fmt.Errorf("si.update: execute query: %w", err)
+		}
+		takeSnapshot(refVal, fields)
+	}
+	err = runHooks(ctx, db, []*T{m}, afterUpdate, afterSave)
 	if err != nil {
-		return fmt.Errorf("si.update: execute query: %w", err)
+		return fmt.Errorf("si.update: %w", err)
 	}
 	return nil
 }
 
-func buildUpdate[T Modeler](ti typeInfo, fields []string) (string, []any) {
+func buildUpdate[T Modeler](ti typeInfo, fields []string, d Dialect) (string, []any) {
 	var columns []string
 	var parameters []any
-	var parameterCount = 1
-	for i := 1; i < len(ti.Columns); i++ {
-		if fields != nil && !slices.Contains(fields, ti.Columns[i]) {
+	for i := range ti.Columns {
+		if slices.Contains(ti.Key, i) || fields != nil && !slices.Contains(fields, ti.Columns[i]) {
 			continue
 		}
-		columns = append(columns, fmt.Sprintf("%s=$%d", ti.Columns[i], parameterCount))
 		parameters = append(parameters, ti.Values[i])
-		parameterCount++
+		columns = append(columns, fmt.Sprintf("%s=%s", d.Quote(ti.Columns[i]), d.Placeholder(len(parameters))))
 	}
 
-	parameters = append(parameters, ti.Values[0])
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d",
-		(*new(T)).GetTable(),
+	var conditions []string
+	for _, index := range ti.Key {
+		parameters = append(parameters, ti.Values[index])
+		conditions = append(conditions, fmt.Sprintf("%s = %s", d.Quote(ti.Columns[index]), d.Placeholder(len(parameters))))
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		d.Quote((*new(T)).GetTable()),
 		strings.Join(columns, ","),
-		parameterCount,
+		strings.Join(conditions, " AND "),
 	)
 	return query, parameters
 }
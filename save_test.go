@@ -0,0 +1,59 @@
+package si_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/derivatan/si"
+)
+
+type counter struct {
+	si.ModelOf[int64]
+	Name string
+}
+
+func (c counter) GetTable() string { return "counters" }
+
+// fakeSqlDB is a `si.SqlDB` that can only execute, to test `si.WrapDB`.
+type fakeSqlDB struct {
+	lastID int64
+}
+
+func (db *fakeSqlDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (db *fakeSqlDB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *fakeSqlDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (db *fakeSqlDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	db.lastID++
+	return fakeResult(db.lastID), nil
+}
+
+func TestInsertLastInsertID(t *testing.T) {
+	for name, db := range map[string]si.DB{
+		"DB":     &fakeDB{dialect: si.MySQL},
+		"WrapDB": si.WrapDBDialect(&fakeSqlDB{}, si.MySQL),
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := counter{Name: "a"}
+			err := si.Save(db, &c)
+			if err != nil || c.ID == nil || *c.ID != 1 {
+				t.Fatal(err, c.ID)
+			}
+			cs := []counter{{Name: "b"}, {Name: "c"}}
+			err = si.InsertMany(db, cs)
+			if err != nil || *cs[0].ID != 2 || *cs[1].ID != 3 {
+				t.Fatal(err, cs)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package si
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
 
 // This is an example to use SI with the standard `database/sql` library.
 
@@ -11,31 +15,85 @@ import "database/sql"
 type SqlDB interface {
 	Query(query string, args ...any) (*sql.Rows, error)
 	Exec(query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// WrapDB will wrap `db`, and use the dialect of the client.
 func WrapDB(db SqlDB) DB {
+	return WrapDBDialect(db, nil)
+}
+
+// WrapDBDialect is same as WrapDB, but with its own dialect.
+func WrapDBDialect(db SqlDB, dialect Dialect) DB {
 	return &DBWrap{
-		db: db,
+		db:      db,
+		dialect: dialect,
 	}
 }
 
 // DB
 
 type DBWrap struct {
-	db SqlDB
+	db      SqlDB
+	dialect Dialect
+}
+
+func (db *DBWrap) Dialect() Dialect {
+	return db.dialect
 }
 
 func (db *DBWrap) Query(query string, args ...any) (Rows, error) {
-	result, err := db.db.Query(query, args...)
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+func (db *DBWrap) Exec(query string, args ...any) (any, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *DBWrap) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	result, err := db.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	return &RowsWrap{rows: result}, nil
 }
 
-func (db *DBWrap) Exec(query string, args ...any) (any, error) {
-	_, err := db.db.Exec(query, args...)
-	return nil, err
+func (db *DBWrap) ExecContext(ctx context.Context, query string, args ...any) (any, error) {
+	result, err := db.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (db *DBWrap) BeginTx(ctx context.Context) (Tx, error) {
+	beginner, ok := db.db.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("the wrapped db can not begin a transaction")
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TxWrap{DBWrap: DBWrap{db: tx, dialect: db.dialect}, tx: tx}, nil
+}
+
+// Tx
+
+type TxWrap struct {
+	DBWrap
+	tx *sql.Tx
+}
+
+func (w *TxWrap) Commit() error {
+	return w.tx.Commit()
+}
+
+func (w *TxWrap) Rollback() error {
+	return w.tx.Rollback()
 }
 
 // Rows
@@ -52,6 +110,10 @@ func (w *RowsWrap) Scan(a ...any) error {
 	return w.rows.Scan(a...)
 }
 
+func (w *RowsWrap) Err() error {
+	return w.rows.Err()
+}
+
 func (w *RowsWrap) Close() error {
 	return w.rows.Close()
 }
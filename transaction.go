@@ -0,0 +1,137 @@
+package si
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Tx is a `DB` that is inside a transaction.
+type Tx interface {
+	DB
+	Commit() error
+	Rollback() error
+}
+
+// TxBeginner is a `DB` that can start a transaction.
+// This must be implemented by the `DB` given to `Transaction`.
+type TxBeginner interface {
+	BeginTx(ctx context.Context) (Tx, error)
+}
+
+// Transaction will run `f` inside a transaction.
+// The transaction is committed if `f` returns nil, and rolled back if it returns an error or panics.
+// If `db` is already a transaction from this function, a savepoint is used instead.
+func Transaction(db DB, f func(tx DB) error) error {
+	return TransactionContext(context.Background(), db, f)
+}
+
+// TransactionContext is same as Transaction, but with a context.
+func TransactionContext(ctx context.Context, db DB, f func(tx DB) error) error {
+	if t, ok := db.(*transaction); ok {
+		return t.savepoint(ctx, f)
+	}
+
+	beginner, ok := db.(TxBeginner)
+	if !ok {
+		return errors.New("si.transaction: db does not implement TxBeginner")
+	}
+	c := clientOf(db)
+	tx, err := beginner.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("si.transaction: begin: %w", err)
+	}
+	c.log("BEGIN")
+
+	t := &transaction{tx: tx}
+	err = run(t, f, func() error {
+		c.log("ROLLBACK")
+		return tx.Rollback()
+	})
+	if err != nil {
+		return err
+	}
+
+	c.log("COMMIT")
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("si.transaction: commit: %w", err)
+	}
+	return nil
+}
+
+type transaction struct {
+	tx    Tx
+	depth int
+}
+
+func (t *transaction) Query(query string, args ...any) (Rows, error) {
+	return t.tx.Query(query, args...)
+}
+
+func (t *transaction) Exec(query string, args ...any) (any, error) {
+	return t.tx.Exec(query, args...)
+}
+
+func (t *transaction) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return queryContext(ctx, t.tx, query, args...)
+}
+
+func (t *transaction) ExecContext(ctx context.Context, query string, args ...any) (any, error) {
+	return execContext(ctx, t.tx, query, args...)
+}
+
+func (t *transaction) client() *Client {
+	return clientOf(t.tx)
+}
+
+func (t *transaction) Dialect() Dialect {
+	return clientOf(t.tx).dialect
+}
+
+func (t *transaction) savepoint(ctx context.Context, f func(tx DB) error) error {
+	name := fmt.Sprintf("si_savepoint_%d", t.depth+1)
+	err := t.exec(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return fmt.Errorf("si.transaction: savepoint: %w", err)
+	}
+
+	nested := &transaction{tx: t.tx, depth: t.depth + 1}
+	err = run(nested, f, func() error {
+		return t.exec(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.exec(ctx, "RELEASE SAVEPOINT "+name)
+	if err != nil {
+		return fmt.Errorf("si.transaction: release savepoint: %w", err)
+	}
+	return nil
+}
+
+func (t *transaction) exec(ctx context.Context, query string) error {
+	_, err := clientOf(t.tx).exec(ctx, t.tx, query)
+	return err
+}
+
+// run will call `f` and call `rollback` if it fails or panics.
+func run(t *transaction, f func(tx DB) error, rollback func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollback()
+			panic(p)
+		}
+	}()
+
+	err = f(t)
+	if err != nil {
+		rbErr := rollback()
+		if rbErr != nil {
+			return fmt.Errorf("si.transaction: rollback: %w (after: %w)", rbErr, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package si
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator can be implemented by a model to be validated before it is saved, together with the `validate` tags.
+// If it returns a ValidationError, its fields are added to the fields that failed the tags.
+type Validator interface {
+	Validate() error
+}
+
+// ErrValidation matches a ValidationError with `errors.Is`.
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError is returned when a model is not valid, with all fields that failed.
+type ValidationError struct {
+	Table  string
+	Fields []FieldError
+}
+
+// FieldError is one failed rule on a field. `Rule` and `Param` are from the tag, e.g. `max` and `255`.
+type FieldError struct {
+	Field   string
+	Column  string
+	Rule    string
+	Param   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	var fields []string
+	for _, f := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%s %s", f.Column, f.Message))
+	}
+	return fmt.Sprintf("Validation failed on '%s': %s", e.Table, strings.Join(fields, ", "))
+}
+
+func (ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// rule is one rule in a `validate` tag.
+type rule struct {
+	name  string
+	param string
+	limit float64
+	// err is set if the rule is not valid, which is returned when the model is validated.
+	err error
+}
+
+// parseRules will parse the `validate` tag of a field, e.g. `validate:"required,max=255"`.
+// The rules are `required`, `min` and `max`, where `min` and `max` is the length of strings and slices, and the value of numbers.
+// Other rules are ignored, so the tag can be shared with other validators.
+func parseRules(field reflect.StructField) []rule {
+	tag, ok := field.Tag.Lookup("validate")
+	if !ok || tag == "" {
+		return nil
+	}
+	var result []rule
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		r := rule{name: name, param: param}
+		switch name {
+		case "required":
+		case "min", "max":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				r.err = fmt.Errorf("invalid '%s' in the validate tag of '%s'", part, field.Name)
+			}
+			r.limit = limit
+		default:
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// validate will check the rules of all fields in `columns` on the model that `refVal` points to, or all fields if `columns` is nil.
+func validate(refVal reflect.Value, columns []string) error {
+	info := getModelInfo(refVal.Type().Elem())
+	m := refVal.Interface().(interface{ GetTable() string })
+	result := ValidationError{Table: m.GetTable()}
+	for i, rules := range info.rules {
+		if columns != nil && !slices.Contains(columns, info.Columns[i]) {
+			continue
+		}
+		field := refVal.Elem().FieldByIndex(info.indexes[i])
+		for _, r := range rules {
+			message, ok, err := r.check(field)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				result.Fields = append(result.Fields, FieldError{
+					Field:   info.Names[i],
+					Column:  info.Columns[i],
+					Rule:    r.name,
+					Param:   r.param,
+					Message: message,
+				})
+			}
+		}
+	}
+
+	if v, ok := refVal.Interface().(Validator); ok {
+		err := v.Validate()
+		var ve ValidationError
+		if errors.As(err, &ve) {
+			result.Fields = append(result.Fields, ve.Fields...)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(result.Fields) > 0 {
+		return result
+	}
+	return nil
+}
+
+// check will return false and a message if the value of the field does not follow the rule.
+// It is an error if the rule is not valid, or can not be used on the type of the field.
+// `min` and `max` are not checked on nil pointers, use `required` for that.
+func (r rule) check(field reflect.Value) (string, bool, error) {
+	if r.err != nil {
+		return "", false, r.err
+	}
+	if r.name == "required" {
+		_, ok := fieldValue(field)
+		return "is required", ok, nil
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", true, nil
+		}
+		field = field.Elem()
+	}
+
+	var value float64
+	unit := ""
+	switch field.Kind() {
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(field.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value = float64(field.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = field.Float()
+	default:
+		return "", false, fmt.Errorf("the rule '%s' can not be used on '%s'", r.name, field.Type())
+	}
+	if r.name == "min" && value < r.limit {
+		return fmt.Sprintf("must be at least %s%s", r.param, unit), false, nil
+	}
+	if r.name == "max" && value > r.limit {
+		return fmt.Sprintf("must be at most %s%s", r.param, unit), false, nil
+	}
+	return "", true, nil
+}